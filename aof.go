@@ -0,0 +1,191 @@
+package mem
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"time"
+)
+
+// aofMinCompaction is the minimum number of records in the log before it is
+// compacted; past that, the log is compacted when it holds more than twice
+// as many records as the Store holds entries.
+const aofMinCompaction = 1024
+
+// aofRecord is a mutation in the append-only log.
+type aofRecord struct {
+	Op      string        `json:"op"` // "set" or "del"
+	Key     string        `json:"key"`
+	Data    []byte        `json:"data,omitempty"`
+	ValidTo int64         `json:"validTo,omitempty"`
+	Idle    time.Duration `json:"idle,omitempty"`
+}
+
+// aof is an append-only log of the mutations of a Store.
+type aof struct {
+	path    string
+	f       *os.File
+	w       *bufio.Writer
+	enc     *json.Encoder
+	records int   // in the log file
+	err     error // sticky: logging stops at the first error
+}
+
+// openAOF replays the log at path into the Store, then opens it for
+// appending. A truncated last record, as left by a crash, is ignored.
+// The caller must hold the write lock, or have exclusive access to s.
+func (s *Store) openAOF(path string) error {
+	a := &aof{path: path}
+	s.aof = a
+
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		a.err = err
+		return err
+	}
+
+	now := s.clock.Now()
+	dec := json.NewDecoder(bufio.NewReader(f))
+	for {
+		var rec aofRecord
+		if err := dec.Decode(&rec); err != nil {
+			break // io.EOF, or a truncated record
+		}
+		a.records++
+
+		switch rec.Op {
+		case "set":
+			e := entry{data: rec.Data, validTo: rec.ValidTo, idle: rec.Idle}
+			if e.validAt(now) {
+				s.put(rec.Key, e)
+			} else {
+				s.remove(rec.Key, Expired)
+			}
+		case "del":
+			s.remove(rec.Key, Deleted)
+		}
+	}
+	// replaying is neither removing nor mutating
+	s.removed = nil
+	s.mutations = nil
+
+	// Compact right away: this also drops a truncated last record, which
+	// appending would otherwise corrupt.
+	f.Close()
+	a.err = s.compactAOF()
+	return a.err
+}
+
+// logSet appends the assignment of e to the key to the log, if any.
+func (s *Store) logSet(k string, e entry) {
+	s.appendAOF(aofRecord{Op: "set", Key: k, Data: e.data, ValidTo: e.validTo, Idle: e.idle})
+}
+
+// logDel appends the removal of the key to the log, if any.
+func (s *Store) logDel(k string) {
+	s.appendAOF(aofRecord{Op: "del", Key: k})
+}
+
+func (s *Store) appendAOF(rec aofRecord) {
+	a := s.aof
+	if a == nil || a.err != nil || a.f == nil {
+		return
+	}
+	a.err = a.enc.Encode(rec)
+	a.records++
+}
+
+// flushAOF writes the buffered records to the log file, compacting it first
+// if it grew too large. The caller must hold the write lock.
+func (s *Store) flushAOF() {
+	a := s.aof
+	if a == nil || a.err != nil || a.f == nil {
+		return
+	}
+
+	if a.records > aofMinCompaction && a.records > 2*len(s.m) {
+		a.err = s.compactAOF()
+		return
+	}
+	a.err = a.w.Flush()
+}
+
+// compactAOF rewrites the log with one record per valid entry, then reopens
+// it for appending. The caller must hold the write lock.
+func (s *Store) compactAOF() error {
+	a := s.aof
+	if a.f != nil {
+		a.f.Close()
+		a.f = nil
+	}
+
+	tmp := a.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+
+	now := s.clock.Now()
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	var records int
+	for k, e := range s.m {
+		if !e.validAt(now) {
+			continue
+		}
+		if err := enc.Encode(aofRecord{Op: "set", Key: k, Data: e.data, ValidTo: e.validTo, Idle: e.idle}); err != nil {
+			f.Close()
+			return err
+		}
+		records++
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, a.path); err != nil {
+		return err
+	}
+
+	f, err = os.OpenFile(a.path, os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+
+	a.f = f
+	a.w = bufio.NewWriter(f)
+	a.enc = json.NewEncoder(a.w)
+	a.records = records
+	return nil
+}
+
+// closeAOF flushes and closes the log file, returning the first error
+// encountered by the log.
+func (s *Store) closeAOF() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a := s.aof
+	if a.f == nil {
+		return a.err
+	}
+
+	if a.err == nil {
+		a.err = a.w.Flush()
+	}
+	if a.err == nil {
+		a.err = a.f.Sync()
+	}
+	if err := a.f.Close(); a.err == nil {
+		a.err = err
+	}
+	a.f = nil
+	return a.err
+}
@@ -0,0 +1,97 @@
+package mem
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWithAOF(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "store.aof")
+
+	s := New(WithAOF(path))
+	s.Set(ctx, "a", value("1"))
+	s.Set(ctx, "b", value("2"))
+	s.SetWithTimeout(ctx, "c", value("3"), time.Minute)
+	s.Set(ctx, "a", value("4"))
+	s.Delete(ctx, "b")
+	if err := s.Close(); err != nil {
+		t.Fatalf("closing: %v", err)
+	}
+
+	// simulate a crash in the middle of a write
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"op":"set","key":"trunc`)
+	f.Close()
+
+	s = New(WithAOF(path))
+	defer s.Close()
+
+	if err := s.Ping(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var v value
+	if ok, _ := s.Get(ctx, "a", &v); !ok || v != "4" {
+		t.Errorf("expected %q, found %q", "4", v)
+	}
+	if ok, _ := s.Exists(ctx, "b"); ok {
+		t.Error("expected the deleted entry to stay deleted")
+	}
+	if _, ok, _ := s.TTL(ctx, "c"); !ok {
+		t.Error("expected the deadline to be preserved")
+	}
+	if s.aof.records != 2 {
+		t.Errorf("expected the log to be compacted to 2 records, found %d", s.aof.records)
+	}
+}
+
+func TestAOFCompaction(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "store.aof")
+
+	s := New(WithAOF(path))
+	defer s.Close()
+
+	for i := 0; i < 2*aofMinCompaction; i++ {
+		s.Set(ctx, "counter", value("1"))
+	}
+
+	s.mu.RLock()
+	records := s.aof.records
+	s.mu.RUnlock()
+	if records > aofMinCompaction {
+		t.Errorf("expected the log to be compacted, found %d records", records)
+	}
+}
+
+func TestAOFReplayHooks(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "store.aof")
+
+	s := New(WithAOF(path))
+	s.Set(ctx, "a", value("1"))
+	s.Delete(ctx, "a")
+	s.Set(ctx, "b", value("2"))
+	s.Close()
+
+	var log []string
+	s = New(
+		WithOnSet(func(k string, data []byte) { log = append(log, "set "+k) }),
+		WithOnDelete(func(k string, data []byte) { log = append(log, "delete "+k) }),
+		WithAOF(path),
+	)
+	defer s.Close()
+
+	s.Set(ctx, "c", value("3"))
+	if want := []string{"set c"}; !reflect.DeepEqual(log, want) {
+		t.Errorf("expected %q, found %q", want, log)
+	}
+}
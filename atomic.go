@@ -0,0 +1,398 @@
+package mem
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"strconv"
+)
+
+// ErrNotInteger is returned when Incr or Decr find a value that is not a
+// JSON integer.
+var ErrNotInteger = errors.New("the value is not an integer")
+
+// ErrOverflow is returned when the result of Incr or Decr does not fit in
+// an int64.
+var ErrOverflow = errors.New("the result overflows an int64")
+
+// SetNX assigns the given value to the given key, only if the key is not
+// already present. Returns true if the value was written.
+// The returned error is not nil if the context is Done, if marshaling
+// fails, or if the write would exceed the quota of the bucket of the key.
+func (s *Store) SetNX(ctx context.Context, k string, v json.Marshaler) (bool, error) {
+	select {
+	case <-ctx.Done():
+		return false, ctx.Err()
+	default:
+	}
+
+	b, err := s.marshal(k, v)
+	if err != nil {
+		return false, err
+	}
+
+	s.mu.Lock()
+	defer s.unlock()
+	select {
+	case <-ctx.Done():
+		return false, ctx.Err()
+	default:
+	}
+	if s.frozen {
+		return false, ErrReadOnly
+	}
+
+	if e, ok := s.m[k]; ok && e.validAt(s.clock.Now()) {
+		return false, nil
+	}
+
+	e := s.newEntry(k, b)
+	if err := s.admit(k, e); err != nil {
+		return false, err
+	}
+	s.put(k, e)
+	return true, nil
+}
+
+// CompareAndSwap assigns newV to the given key, only if the stored value is
+// byte-for-byte equal to the marshaled oldV. Returns true if the value was
+// swapped. Like Set, a successful swap clears any deadline.
+// The returned error is not nil if the context is Done or if marshaling
+// fails.
+func (s *Store) CompareAndSwap(ctx context.Context, k string, oldV, newV json.Marshaler) (bool, error) {
+	select {
+	case <-ctx.Done():
+		return false, ctx.Err()
+	default:
+	}
+
+	o, err := s.marshal(k, oldV)
+	if err != nil {
+		return false, err
+	}
+
+	b, err := s.marshal(k, newV)
+	if err != nil {
+		return false, err
+	}
+
+	s.mu.Lock()
+	defer s.unlock()
+	select {
+	case <-ctx.Done():
+		return false, ctx.Err()
+	default:
+	}
+	if s.frozen {
+		return false, ErrReadOnly
+	}
+
+	if ok, err := s.holds(k, o); !ok || err != nil {
+		return false, err
+	}
+
+	e := s.newEntry(k, b)
+	if err := s.admit(k, e); err != nil {
+		return false, err
+	}
+	s.put(k, e)
+	return true, nil
+}
+
+// CompareAndDelete removes the entry corresponding to the given key, only if
+// the stored value is byte-for-byte equal to the marshaled expected value.
+// Returns true if the entry was removed.
+// The returned error is not nil if the context is Done or if marshaling
+// fails.
+func (s *Store) CompareAndDelete(ctx context.Context, k string, expected json.Marshaler) (bool, error) {
+	select {
+	case <-ctx.Done():
+		return false, ctx.Err()
+	default:
+	}
+
+	o, err := s.marshal(k, expected)
+	if err != nil {
+		return false, err
+	}
+
+	s.mu.Lock()
+	defer s.unlock()
+	select {
+	case <-ctx.Done():
+		return false, ctx.Err()
+	default:
+	}
+	if s.frozen {
+		return false, ErrReadOnly
+	}
+
+	if ok, err := s.holds(k, o); !ok || err != nil {
+		return false, err
+	}
+
+	s.remove(k, Deleted)
+	return true, nil
+}
+
+// GetSet assigns newV to the given key and unmarshals the previous value, if
+// any, into oldV, in a single critical section. Returns true if a previous
+// value existed.
+// The returned error is not nil if the context is Done or if marshaling or
+// unmarshaling fails.
+func (s *Store) GetSet(ctx context.Context, k string, newV json.Marshaler, oldV json.Unmarshaler) (bool, error) {
+	select {
+	case <-ctx.Done():
+		return false, ctx.Err()
+	default:
+	}
+
+	b, err := s.marshal(k, newV)
+	if err != nil {
+		return false, err
+	}
+
+	s.mu.Lock()
+	defer s.unlock()
+	select {
+	case <-ctx.Done():
+		return false, ctx.Err()
+	default:
+	}
+	if s.frozen {
+		return false, ErrReadOnly
+	}
+
+	e, existed := s.m[k]
+	existed = existed && e.validAt(s.clock.Now())
+
+	next := s.newEntry(k, b)
+	if err := s.admit(k, next); err != nil {
+		return false, err
+	}
+	s.put(k, next)
+
+	if !existed {
+		return false, nil
+	}
+	data, err := s.value(e)
+	if err != nil {
+		return true, err
+	}
+	return true, oldV.UnmarshalJSON(data)
+}
+
+// GetOrSet unmarshals the value corresponding to the key into out, if
+// present. Otherwise it assigns v to the key and unmarshals it into out.
+// Returns true if the value was already present.
+// The returned error is not nil if the context is Done or if marshaling or
+// unmarshaling fails.
+func (s *Store) GetOrSet(ctx context.Context, k string, v json.Marshaler, out json.Unmarshaler) (bool, error) {
+	select {
+	case <-ctx.Done():
+		return false, ctx.Err()
+	default:
+	}
+
+	b, err := s.marshal(k, v)
+	if err != nil {
+		return false, err
+	}
+
+	s.mu.Lock()
+	defer s.unlock()
+	select {
+	case <-ctx.Done():
+		return false, ctx.Err()
+	default:
+	}
+
+	if e, ok := s.m[k]; ok && e.validAt(s.clock.Now()) {
+		data, err := s.value(e)
+		if err != nil {
+			return true, err
+		}
+		return true, out.UnmarshalJSON(data)
+	}
+	if s.frozen {
+		return false, ErrReadOnly
+	}
+
+	e := s.newEntry(k, b)
+	if err := s.admit(k, e); err != nil {
+		return false, err
+	}
+	s.put(k, e)
+	return false, out.UnmarshalJSON(b)
+}
+
+// GetAndDelete unmarshals the value corresponding to the key into v and
+// removes the entry, in a single critical section.
+// If no match is found, returns (false, nil).
+func (s *Store) GetAndDelete(ctx context.Context, k string, v json.Unmarshaler) (bool, error) {
+	select {
+	case <-ctx.Done():
+		return false, ctx.Err()
+	default:
+	}
+
+	s.mu.Lock()
+	defer s.unlock()
+	select {
+	case <-ctx.Done():
+		return false, ctx.Err()
+	default:
+	}
+	if s.frozen {
+		return false, ErrReadOnly
+	}
+
+	e, ok := s.m[k]
+	if !ok {
+		return false, nil
+	}
+
+	s.remove(k, Deleted)
+	if !e.validAt(s.clock.Now()) {
+		return false, nil
+	}
+	data, err := s.value(e)
+	if err != nil {
+		return true, err
+	}
+	return true, v.UnmarshalJSON(data)
+}
+
+// Update runs fn with the value corresponding to the key under the write
+// lock, and stores the returned bytes in its place. The exists argument
+// reports whether the key was found; data is a copy that fn may modify.
+// If fn returns an error, the store is left unchanged and the error is
+// returned. If fn returns a nil slice, the entry is removed. The deadline of
+// an existing entry is preserved; a new entry gets the default TTL, if any.
+func (s *Store) Update(ctx context.Context, k string, fn func(data []byte, exists bool) ([]byte, error)) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	s.mu.Lock()
+	defer s.unlock()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+	if s.frozen {
+		return ErrReadOnly
+	}
+
+	e, ok := s.m[k]
+	ok = ok && e.validAt(s.clock.Now())
+
+	var data []byte
+	if ok {
+		stored, err := s.value(e)
+		if err != nil {
+			return err
+		}
+		data = append([]byte(nil), stored...)
+	}
+
+	b, err := fn(data, ok)
+	if err != nil {
+		return err
+	}
+
+	if b == nil {
+		s.remove(k, Deleted)
+		return nil
+	}
+	if err := s.validate(k, b); err != nil {
+		return err
+	}
+
+	if ok {
+		e.data = s.seal(b)
+	} else {
+		e = s.newEntry(k, b)
+	}
+	if err := s.admit(k, e); err != nil {
+		return err
+	}
+	s.put(k, e)
+	return nil
+}
+
+// Incr treats the value corresponding to the key as a JSON integer and adds
+// delta to it, atomically. A missing key is created at zero before the
+// addition. Returns the new value.
+// Err is ErrNotInteger if the stored value is not an integer, or
+// ErrOverflow if the result does not fit in an int64; nothing is stored in
+// either case.
+func (s *Store) Incr(ctx context.Context, k string, delta int64) (int64, error) {
+	var n int64
+	err := s.Update(ctx, k, func(data []byte, exists bool) ([]byte, error) {
+		if exists {
+			var err error
+			n, err = strconv.ParseInt(string(bytes.TrimSpace(data)), 10, 64)
+			if err != nil {
+				return nil, ErrNotInteger
+			}
+		}
+		if (delta > 0 && n > math.MaxInt64-delta) || (delta < 0 && n < math.MinInt64-delta) {
+			return nil, ErrOverflow
+		}
+		n += delta
+		return strconv.AppendInt(nil, n, 10), nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// Decr is equivalent to Incr with a negated delta.
+func (s *Store) Decr(ctx context.Context, k string, delta int64) (int64, error) {
+	if delta == math.MinInt64 {
+		return 0, ErrOverflow
+	}
+	return s.Incr(ctx, k, -delta)
+}
+
+// Append concatenates data onto the value corresponding to the key,
+// atomically. A missing key is created with data as its value. Returns the
+// length of the resulting value.
+// The stored bytes are not validated as JSON.
+func (s *Store) Append(ctx context.Context, k string, data []byte) (int, error) {
+	var n int
+	err := s.Update(ctx, k, func(stored []byte, exists bool) ([]byte, error) {
+		b := append(stored, data...)
+		if b == nil {
+			b = []byte{}
+		}
+		n = len(b)
+		return b, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// holds reports whether a valid entry corresponding to the key holds exactly
+// the given bytes. The caller must hold the lock.
+func (s *Store) holds(k string, b []byte) (bool, error) {
+	e, ok := s.m[k]
+	if !ok || !e.validAt(s.clock.Now()) {
+		return false, nil
+	}
+
+	data, err := s.value(e)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(data, b), nil
+}
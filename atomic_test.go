@@ -0,0 +1,331 @@
+package mem_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/gokv/mem"
+)
+
+func TestSetNX(t *testing.T) {
+	s := mem.New()
+	defer s.Close()
+
+	ok, err := s.SetNX(context.Background(), "key", String("first"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !ok {
+		t.Error("expected the first SetNX to write")
+	}
+
+	ok, err = s.SetNX(context.Background(), "key", String("second"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ok {
+		t.Error("expected the second SetNX not to write")
+	}
+
+	var v String
+	if _, err := s.Get(context.Background(), "key", &v); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v != "first" {
+		t.Errorf("expected %q, found %q", "first", v)
+	}
+}
+
+func TestCompareAndSwap(t *testing.T) {
+	s := mem.New()
+	defer s.Close()
+
+	if err := s.Set(context.Background(), "key", String("1")); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range [...]struct {
+		name     string
+		key      string
+		old, new String
+		want     bool
+		stored   String
+	}{
+		{"swaps on match", "key", "1", "2", true, "2"},
+		{"keeps on mismatch", "key", "1", "3", false, "2"},
+		{"misses unset key", "unset key", "2", "3", false, "2"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ok, err := s.CompareAndSwap(context.Background(), tc.key, tc.old, tc.new)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if ok != tc.want {
+				t.Errorf("expected %v, found %v", tc.want, ok)
+			}
+
+			var v String
+			if _, err := s.Get(context.Background(), "key", &v); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if v != tc.stored {
+				t.Errorf("expected %q, found %q", tc.stored, v)
+			}
+		})
+	}
+}
+
+func TestCompareAndDelete(t *testing.T) {
+	s := mem.New()
+	defer s.Close()
+
+	if err := s.Set(context.Background(), "key", String("1")); err != nil {
+		t.Fatal(err)
+	}
+
+	ok, err := s.CompareAndDelete(context.Background(), "key", String("2"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ok {
+		t.Error("expected no deletion on mismatch")
+	}
+
+	ok, err = s.CompareAndDelete(context.Background(), "key", String("1"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !ok {
+		t.Error("expected a deletion on match")
+	}
+
+	var v String
+	if found, _ := s.Get(context.Background(), "key", &v); found {
+		t.Errorf("key %q unexpectedly found: %q", "key", v)
+	}
+}
+
+func TestGetSet(t *testing.T) {
+	s := mem.New()
+	defer s.Close()
+
+	var old String
+	existed, err := s.GetSet(context.Background(), "key", String("1"), &old)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if existed {
+		t.Errorf("unexpected previous value: %q", old)
+	}
+
+	existed, err = s.GetSet(context.Background(), "key", String("2"), &old)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !existed {
+		t.Error("expected a previous value")
+	}
+	if old != "1" {
+		t.Errorf("expected previous value %q, found %q", "1", old)
+	}
+
+	var v String
+	if _, err := s.Get(context.Background(), "key", &v); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v != "2" {
+		t.Errorf("expected %q, found %q", "2", v)
+	}
+}
+
+func TestGetOrSet(t *testing.T) {
+	s := mem.New()
+	defer s.Close()
+
+	var v String
+	loaded, err := s.GetOrSet(context.Background(), "key", String("1"), &v)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if loaded {
+		t.Error("expected the value to be stored, not loaded")
+	}
+	if v != "1" {
+		t.Errorf("expected %q, found %q", "1", v)
+	}
+
+	loaded, err = s.GetOrSet(context.Background(), "key", String("2"), &v)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !loaded {
+		t.Error("expected the value to be loaded")
+	}
+	if v != "1" {
+		t.Errorf("expected %q, found %q", "1", v)
+	}
+}
+
+func TestGetAndDelete(t *testing.T) {
+	s := mem.New()
+	defer s.Close()
+
+	if err := s.Set(context.Background(), "key", String("token")); err != nil {
+		t.Fatal(err)
+	}
+
+	var v String
+	ok, err := s.GetAndDelete(context.Background(), "key", &v)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !ok {
+		t.Fatal("value expected, not found")
+	}
+	if v != "token" {
+		t.Errorf("expected %q, found %q", "token", v)
+	}
+
+	ok, err = s.GetAndDelete(context.Background(), "key", &v)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ok {
+		t.Error("expected the entry to be gone after the first GetAndDelete")
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	s := mem.New()
+	defer s.Close()
+
+	appendX := func(data []byte, exists bool) ([]byte, error) {
+		return append(data, 'x'), nil
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := s.Update(context.Background(), "key", appendX); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	var v String
+	if _, err := s.Get(context.Background(), "key", &v); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v != "xxx" {
+		t.Errorf("expected %q, found %q", "xxx", v)
+	}
+
+	t.Run("leaves the value on error", func(t *testing.T) {
+		failure := errors.New("failure")
+		err := s.Update(context.Background(), "key", func(data []byte, exists bool) ([]byte, error) {
+			return []byte("changed"), failure
+		})
+		if err != failure {
+			t.Errorf("expected error %v, found %v", failure, err)
+		}
+
+		if _, err := s.Get(context.Background(), "key", &v); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if v != "xxx" {
+			t.Errorf("expected %q, found %q", "xxx", v)
+		}
+	})
+
+	t.Run("removes on nil", func(t *testing.T) {
+		err := s.Update(context.Background(), "key", func(data []byte, exists bool) ([]byte, error) {
+			return nil, nil
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if ok, _ := s.Get(context.Background(), "key", &v); ok {
+			t.Errorf("key %q unexpectedly found: %q", "key", v)
+		}
+	})
+}
+
+func TestIncr(t *testing.T) {
+	s := mem.New()
+	defer s.Close()
+
+	for _, tc := range [...]struct {
+		name  string
+		op    func(context.Context, string, int64) (int64, error)
+		delta int64
+		want  int64
+	}{
+		{"creates at zero", s.Incr, 5, 5},
+		{"increments", s.Incr, 2, 7},
+		{"decrements", s.Decr, 10, -3},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			n, err := tc.op(context.Background(), "counter", tc.delta)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if n != tc.want {
+				t.Errorf("expected %d, found %d", tc.want, n)
+			}
+		})
+	}
+
+	t.Run("fails on non-integer", func(t *testing.T) {
+		if err := s.Set(context.Background(), "key", String(`"text"`)); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.Incr(context.Background(), "key", 1); err != mem.ErrNotInteger {
+			t.Errorf("expected error %v, found %v", mem.ErrNotInteger, err)
+		}
+	})
+
+	t.Run("fails on overflow", func(t *testing.T) {
+		ctx := context.Background()
+		if _, err := s.Incr(ctx, "max", math.MaxInt64); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.Incr(ctx, "max", 1); err != mem.ErrOverflow {
+			t.Errorf("expected error %v, found %v", mem.ErrOverflow, err)
+		}
+		if _, err := s.Decr(ctx, "min", math.MinInt64); err != mem.ErrOverflow {
+			t.Errorf("expected error %v, found %v", mem.ErrOverflow, err)
+		}
+		if n, _ := s.Incr(ctx, "max", 0); n != math.MaxInt64 {
+			t.Errorf("expected the value to be left unchanged, found %d", n)
+		}
+	})
+}
+
+func TestAppend(t *testing.T) {
+	s := mem.New()
+	defer s.Close()
+
+	for _, line := range []string{"{\"a\":1}\n", "{\"b\":2}\n"} {
+		if _, err := s.Append(context.Background(), "log", []byte(line)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	n, err := s.Append(context.Background(), "log", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := String("{\"a\":1}\n{\"b\":2}\n")
+	if n != len(want) {
+		t.Errorf("expected length %d, found %d", len(want), n)
+	}
+
+	var v String
+	if _, err := s.Get(context.Background(), "log", &v); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v != want {
+		t.Errorf("expected %q, found %q", want, v)
+	}
+}
@@ -0,0 +1,57 @@
+package mem
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+)
+
+// loadSnapshot loads the snapshot at path, if it exists.
+func (s *Store) loadSnapshot(path string) error {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return s.LoadFrom(context.Background(), f)
+}
+
+// autoSnapshot saves a snapshot to the configured path, and records the
+// outcome for Ping.
+func (s *Store) autoSnapshot(ctx context.Context) {
+	err := s.saveSnapshot(ctx, s.snapshotPath)
+	if err != nil && s.logger != nil {
+		s.logger.Log(EventSnapshotError, "", "path", s.snapshotPath, "error", err)
+	}
+
+	s.mu.Lock()
+	s.snapshotErr = err
+	s.mu.Unlock()
+}
+
+// saveSnapshot writes a snapshot to a temporary file in the directory of
+// path, then atomically renames it to path.
+func (s *Store) saveSnapshot(ctx context.Context, path string) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name()) // no-op after a successful rename
+
+	if err := s.SaveTo(ctx, f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
@@ -0,0 +1,41 @@
+package mem_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gokv/mem"
+)
+
+func TestWithAutoSnapshot(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "store.snapshot")
+
+	s := mem.New(mem.WithAutoSnapshot(path, 10*time.Millisecond))
+	s.Set(ctx, "key", String(`"value"`))
+
+	time.Sleep(50 * time.Millisecond)
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("expected a periodic snapshot: %v", err)
+	}
+
+	s.Set(ctx, "last", String(`"write"`))
+	if err := s.Close(); err != nil {
+		t.Fatalf("closing: %v", err)
+	}
+
+	s = mem.New(mem.WithAutoSnapshot(path, 0))
+	defer s.Close()
+
+	if err := s.Ping(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, k := range []string{"key", "last"} {
+		if ok, _ := s.Exists(ctx, k); !ok {
+			t.Errorf("expected %q to be loaded", k)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package mem
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/gokv/store"
+)
+
+// bucketSeparator separates the name of a Bucket from the keys it holds.
+const bucketSeparator = "\x00"
+
+// Bucket is a namespace of a Store: its keys are isolated from the keys of
+// the other buckets, and from the keys set directly on the Store. It is
+// implemented by prefixing its keys, so all the options of the Store, such
+// as its capacity, apply to the buckets as a whole.
+//
+// Bucket implements store.Store, and is safe for concurrent use.
+type Bucket struct {
+	s      *Store
+	prefix string
+}
+
+// Bucket returns the bucket with the given name, which must not contain NUL
+// bytes. Buckets are not created: the handles with the same name access the
+// same keys.
+func (s *Store) Bucket(name string) *Bucket {
+	return &Bucket{s: s, prefix: name + bucketSeparator}
+}
+
+// WithPrefix returns a view of the keys starting with prefix: the prefix is
+// prepended to the keys passed to the view, and stripped from the keys it
+// returns. Unlike Bucket, the view shares its keys with the Store, so
+// s.WithPrefix("user:").Get(ctx, "1", v) reads the key "user:1".
+func (s *Store) WithPrefix(prefix string) store.Store {
+	return &Bucket{s: s, prefix: prefix}
+}
+
+// Get returns the value corresponding the key, and a nil error.
+// If no match is found, returns (false, nil).
+func (b *Bucket) Get(ctx context.Context, k string, v json.Unmarshaler) (bool, error) {
+	return b.s.Get(ctx, b.prefix+k, v)
+}
+
+// GetAll returns all the values of the bucket.
+// Error is non-nil if the context is Done or if unmarshaling fails.
+func (b *Bucket) GetAll(ctx context.Context, c store.Collection) error {
+	return b.s.getWhere(ctx, func(k string, _ []byte) bool {
+		return strings.HasPrefix(k, b.prefix)
+	}, func(string) json.Unmarshaler { return c.New() })
+}
+
+// Add persists a new object in the bucket, and returns its unique key, as
+// generated by the Store.
+// Err is non-nil in case of failure.
+func (b *Bucket) Add(ctx context.Context, v json.Marshaler) (string, error) {
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	default:
+	}
+
+	data, err := b.s.marshal("", v)
+	if err != nil {
+		return "", err
+	}
+
+	for i := 0; i <= b.s.addRetries; i++ {
+		k, err := b.s.newKey()
+		if err != nil {
+			return "", err
+		}
+		ok, err := b.s.create(ctx, b.prefix+k, data, time.Time{})
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			return k, nil
+		}
+	}
+	return "", ErrKeyExists
+}
+
+// Set assigns the given value to the given key, possibly overwriting.
+// The returned error is not nil if the context is Done.
+func (b *Bucket) Set(ctx context.Context, k string, v json.Marshaler) error {
+	return b.s.Set(ctx, b.prefix+k, v)
+}
+
+// SetWithTimeout assigns the given value to the given key, possibly
+// overwriting.
+// The assigned key will clear after timeout. The lifespan starts when this
+// function is called.
+func (b *Bucket) SetWithTimeout(ctx context.Context, k string, v json.Marshaler, timeout time.Duration) error {
+	return b.s.SetWithTimeout(ctx, b.prefix+k, v, timeout)
+}
+
+// SetWithDeadline assigns the given value to the given key, possibly
+// overwriting.
+// The assigned key will clear after deadline.
+func (b *Bucket) SetWithDeadline(ctx context.Context, k string, v json.Marshaler, deadline time.Time) error {
+	return b.s.SetWithDeadline(ctx, b.prefix+k, v, deadline)
+}
+
+// Delete removes the corresponding entry if present.
+// Returns a non-nil error if the context is Done.
+func (b *Bucket) Delete(ctx context.Context, k string) (bool, error) {
+	return b.s.Delete(ctx, b.prefix+k)
+}
+
+// Keys returns the keys of all the valid entries of the bucket, in no
+// particular order.
+// Err is non-nil if the context is Done.
+func (b *Bucket) Keys(ctx context.Context) ([]string, error) {
+	keys, err := b.s.keysMatching(ctx, func(k string) bool {
+		return strings.HasPrefix(k, b.prefix)
+	})
+	for i, k := range keys {
+		keys[i] = strings.TrimPrefix(k, b.prefix)
+	}
+	return keys, err
+}
+
+// Clear deletes all the entries of the bucket, and returns their number.
+// Err is non-nil if the context is Done.
+func (b *Bucket) Clear(ctx context.Context) (int, error) {
+	return b.s.DeleteByPrefix(ctx, b.prefix)
+}
+
+// Ping returns the health of the Store.
+func (b *Bucket) Ping(ctx context.Context) error {
+	return b.s.Ping(ctx)
+}
+
+// Close does nothing: the Store is closed separately.
+func (b *Bucket) Close() error {
+	return nil
+}
@@ -0,0 +1,102 @@
+package mem_test
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/gokv/mem"
+	"github.com/gokv/store"
+)
+
+var _ store.Store = (*mem.Bucket)(nil)
+
+func TestBucket(t *testing.T) {
+	ctx := context.Background()
+
+	s := mem.New()
+	defer s.Close()
+
+	users, sessions := s.Bucket("users"), s.Bucket("sessions")
+
+	users.Set(ctx, "1", String(`"alice"`))
+	sessions.Set(ctx, "1", String(`"token"`))
+	s.Set(ctx, "1", String(`"flat"`))
+	k, err := users.Add(ctx, String(`"bob"`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var v String
+	if ok, _ := users.Get(ctx, "1", &v); !ok || v != `"alice"` {
+		t.Errorf("expected %q, found %q", `"alice"`, v)
+	}
+	if ok, _ := sessions.Get(ctx, "1", &v); !ok || v != `"token"` {
+		t.Errorf("expected %q, found %q", `"token"`, v)
+	}
+
+	keys, _ := users.Keys(ctx)
+	want := []string{"1", k}
+	sort.Strings(keys)
+	sort.Strings(want)
+	if len(keys) != 2 || keys[0] != want[0] || keys[1] != want[1] {
+		t.Errorf("expected %q, found %q", want, keys)
+	}
+
+	var c collection
+	if err := users.GetAll(ctx, &c); err != nil || len(c) != 2 {
+		t.Errorf("expected 2 values, found %d (%v)", len(c), err)
+	}
+
+	if n, err := users.Clear(ctx); n != 2 || err != nil {
+		t.Errorf("expected (2, nil), found (%d, %v)", n, err)
+	}
+	if ok, _ := users.Get(ctx, "1", &v); ok {
+		t.Error("expected the bucket to be cleared")
+	}
+	if ok, _ := sessions.Get(ctx, "1", &v); !ok {
+		t.Error("expected the other buckets to be left untouched")
+	}
+	if ok, _ := s.Get(ctx, "1", &v); !ok || v != `"flat"` {
+		t.Errorf("expected %q, found %q", `"flat"`, v)
+	}
+}
+
+func TestWithPrefix(t *testing.T) {
+	ctx := context.Background()
+
+	s := mem.New()
+	defer s.Close()
+
+	users := s.WithPrefix("user:")
+
+	if err := users.Set(ctx, "1", String(`"alice"`)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s.Set(ctx, "session:1", String(`"token"`))
+
+	var v String
+	if ok, _ := s.Get(ctx, "user:1", &v); !ok || v != `"alice"` {
+		t.Errorf("expected %q, found %q", `"alice"`, v)
+	}
+
+	k, err := users.Add(ctx, String(`"bob"`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ok, _ := s.Get(ctx, "user:"+k, &v); !ok || v != `"bob"` {
+		t.Errorf("expected %q, found %q", `"bob"`, v)
+	}
+
+	var c collection
+	if err := users.GetAll(ctx, &c); err != nil || len(c) != 2 {
+		t.Errorf("expected 2 values, found %d (%v)", len(c), err)
+	}
+
+	if ok, _ := users.Delete(ctx, "1"); !ok {
+		t.Error("expected a deletion")
+	}
+	if ok, _ := s.Exists(ctx, "user:1"); ok {
+		t.Error("expected the key to be deleted from the Store")
+	}
+}
@@ -0,0 +1,31 @@
+package mem
+
+import "context"
+
+// GetBytes returns a copy of the raw value corresponding the key.
+// If no match is found, returns (nil, false, nil).
+func (s *Store) GetBytes(ctx context.Context, k string) ([]byte, bool, error) {
+	var b raw
+	ok, err := s.Get(ctx, k, &b)
+	return b, ok, err
+}
+
+// SetBytes assigns a copy of the given raw value to the given key, possibly
+// overwriting. The value is not required to be JSON.
+// The returned error is not nil if the context is Done.
+func (s *Store) SetBytes(ctx context.Context, k string, b []byte) error {
+	return s.Set(ctx, k, raw(append([]byte{}, b...)))
+}
+
+// AddBytes persists a copy of the given raw value and returns its unique
+// key. The value is not required to be JSON.
+// Err is non-nil in case of failure.
+func (s *Store) AddBytes(ctx context.Context, b []byte) (string, error) {
+	return s.Add(ctx, raw(append([]byte{}, b...)))
+}
+
+// UnmarshalJSON copies data into r.
+func (r *raw) UnmarshalJSON(data []byte) error {
+	*r = append((*r)[:0], data...)
+	return nil
+}
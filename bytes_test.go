@@ -0,0 +1,42 @@
+package mem_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/gokv/mem"
+)
+
+func TestBytes(t *testing.T) {
+	s := mem.New()
+	defer s.Close()
+
+	ctx := context.Background()
+	payload := []byte{0x08, 0x96, 0x01} // not JSON
+
+	if err := s.SetBytes(ctx, "proto", payload); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	payload[0] = 0 // the store holds a copy
+
+	b, ok, err := s.GetBytes(ctx, "proto")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !ok || !bytes.Equal(b, []byte{0x08, 0x96, 0x01}) {
+		t.Errorf("expected %x, found %x", []byte{0x08, 0x96, 0x01}, b)
+	}
+
+	k, err := s.AddBytes(ctx, []byte("raw"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if b, ok, _ := s.GetBytes(ctx, k); !ok || string(b) != "raw" {
+		t.Errorf("expected %q, found %q", "raw", b)
+	}
+
+	if b, ok, _ := s.GetBytes(ctx, "unset key"); ok || b != nil {
+		t.Errorf("expected a miss, found %q", b)
+	}
+}
@@ -2,28 +2,85 @@ package mem
 
 import (
 	"context"
+	"sync/atomic"
 	"time"
 )
 
+// Cleanup removes the expired entries, earliest deadline first, until there
+// are no more or the context is Done. If configured
+// WithStaleWhileRevalidate, entries are kept for the grace period after
+// their deadline.
+// The write lock is released every cleanupBatchSize removals, so that readers
+// are never blocked for longer than one batch.
 func (s *Store) Cleanup(ctx context.Context) {
+	s.Purge(ctx)
+}
+
+// PurgeReport describes a run of Purge.
+type PurgeReport struct {
+	// Removed is the number of expired entries removed.
+	Removed int
+
+	// LockHeld is the total time the write lock was held, over all the
+	// batches.
+	LockHeld time.Duration
+
+	// Duration is the total time of the run.
+	Duration time.Duration
+}
+
+// Purge removes the expired entries like Cleanup, and reports what it did.
+// It may be called at any time, for example before taking a snapshot,
+// regardless of the background cleanup.
+// Err is non-nil if the context is Done before all the expired entries are
+// removed.
+func (s *Store) Purge(ctx context.Context) (PurgeReport, error) {
+	var r PurgeReport
+	start := time.Now()
+	defer func() {
+		atomic.AddUint64(&s.stats.cleanupNanos, uint64(time.Since(start)))
+	}()
+
+	now := s.clock.Now().Add(-s.staleGrace).UnixNano()
+	for s.cleanupBatch(ctx, now, &r) {
+	}
+	r.Duration = time.Since(start)
+
+	err := ctx.Err()
+	if err != nil && s.logger != nil {
+		s.logger.Log(EventSlowCleanup, "", "duration", r.Duration, "error", err)
+	}
+	return r, err
+}
+
+// cleanupBatch removes up to cleanupBatchSize entries expired at now, and
+// adds its work to r.
+// Returns true if more entries may be due.
+func (s *Store) cleanupBatch(ctx context.Context, now int64, r *PurgeReport) bool {
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	defer s.unlock()
+	locked := time.Now()
+	defer s.stats.cleanup.since(locked)
+	defer func() { r.LockHeld += time.Since(locked) }()
 
-	now := time.Now()
-	for k, e := range s.m {
+	for i := 0; i < cleanupBatchSize; i++ {
 		select {
 		case <-ctx.Done():
-			return
+			return false
 		default:
 		}
 
-		if !e.validAt(now) {
-			delete(s.m, k)
+		k, ok := s.exp.due(now)
+		if !ok {
+			return false
 		}
+		s.remove(k, Expired)
+		r.Removed++
 	}
+	return true
 }
 
-func start(fn func(context.Context), timeout, interval time.Duration) (stop func()) {
+func start(clock Clock, fn func(context.Context), timeout, interval time.Duration) (stop func()) {
 	ctx, stop := context.WithCancel(context.Background())
 	go func() {
 		for {
@@ -35,7 +92,10 @@ func start(fn func(context.Context), timeout, interval time.Duration) (stop func
 				fn(fnCtx)
 
 				fnCancel()
-				time.Sleep(interval)
+				select {
+				case <-ctx.Done():
+				case <-clock.After(interval):
+				}
 			}
 		}
 	}()
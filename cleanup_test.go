@@ -2,7 +2,6 @@ package mem
 
 import (
 	"context"
-	"errors"
 	"testing"
 	"time"
 )
@@ -19,20 +18,139 @@ func (s value) MarshalJSON() (data []byte, err error) {
 }
 
 func TestCleanup(t *testing.T) {
-	s := New()
+	clock := newFakeClock()
+	s := New(WithClock(clock))
 	defer s.Close()
 
 	key := "key"
+	has := func() bool {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+		_, ok := s.m[key]
+		return ok
+	}
 
-	d := time.Nanosecond
-	s.SetWithTimeout(context.Background(), key, value("wazzup"), d)
-	time.Sleep(d)
-	if _, ok := s.m[key]; !ok {
-		panic(errors.New("expected the value to still be present after short delay"))
+	s.SetWithTimeout(context.Background(), key, value("wazzup"), time.Second)
+	clock.Advance(time.Millisecond)
+	if !has() {
+		t.Fatal("expected the value to still be present after short delay")
 	}
 
-	time.Sleep(time.Millisecond * 1001)
-	if _, ok := s.m[key]; ok {
+	for i := 0; has() && i < 1000; i++ {
+		clock.Advance(cleanupInterval)
+		time.Sleep(time.Millisecond)
+	}
+	if has() {
 		t.Error("expected the value to be garbage collected")
 	}
 }
+
+func TestWithCleanupInterval(t *testing.T) {
+	t.Run("runs at the given interval", func(t *testing.T) {
+		s := New(WithCleanupInterval(time.Millisecond))
+		defer s.Close()
+
+		key := "key"
+		s.SetWithTimeout(context.Background(), key, value("wazzup"), time.Nanosecond)
+
+		time.Sleep(50 * time.Millisecond)
+		s.mu.RLock()
+		_, ok := s.m[key]
+		s.mu.RUnlock()
+		if ok {
+			t.Error("expected the value to be garbage collected")
+		}
+	})
+
+	t.Run("disables the background cleanup", func(t *testing.T) {
+		s := New(WithCleanupInterval(0))
+		defer s.Close()
+
+		key := "key"
+		s.SetWithTimeout(context.Background(), key, value("wazzup"), time.Nanosecond)
+
+		time.Sleep(50 * time.Millisecond)
+		s.mu.RLock()
+		_, ok := s.m[key]
+		s.mu.RUnlock()
+		if !ok {
+			t.Error("expected the value not to be garbage collected")
+		}
+	})
+}
+
+func TestWithOnExpire(t *testing.T) {
+	expired := make(map[string]string)
+	s := New(WithCleanupInterval(0), WithOnExpire(func(k string, data []byte) {
+		expired[k] = string(data)
+	}))
+	defer s.Close()
+
+	ctx := context.Background()
+	s.SetWithTimeout(ctx, "expired", value("1"), -time.Second)
+	s.SetWithTimeout(ctx, "volatile", value("2"), time.Minute)
+
+	s.Cleanup(ctx)
+
+	if len(expired) != 1 || expired["expired"] != "1" {
+		t.Errorf("expected only %q to expire, found %q", "expired", expired)
+	}
+}
+
+func TestCleanupReleasesLock(t *testing.T) {
+	s := New(WithCleanupInterval(0))
+	defer s.Close()
+
+	ctx := context.Background()
+	for i := 0; i < 3*cleanupBatchSize; i++ {
+		s.SetWithTimeout(ctx, string(rune(i)), value("x"), -time.Second)
+	}
+
+	now := time.Now().UnixNano()
+	var r PurgeReport
+	var batches int
+	for s.cleanupBatch(ctx, now, &r) {
+		batches++
+	}
+
+	if batches != 3 {
+		t.Errorf("expected 3 full batches, found %d", batches)
+	}
+	if len(s.m) != 0 {
+		t.Errorf("expected all the entries to be removed, found %d", len(s.m))
+	}
+	if r.Removed != 3*cleanupBatchSize {
+		t.Errorf("expected %d removals to be reported, found %d", 3*cleanupBatchSize, r.Removed)
+	}
+}
+
+func TestPurge(t *testing.T) {
+	s := New(WithCleanupInterval(0))
+	defer s.Close()
+
+	ctx := context.Background()
+	s.SetWithTimeout(ctx, "expired", value("1"), -time.Second)
+	s.SetWithTimeout(ctx, "also expired", value("2"), -time.Second)
+	s.SetWithTimeout(ctx, "volatile", value("3"), time.Minute)
+
+	r, err := s.Purge(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.Removed != 2 {
+		t.Errorf("expected 2 removals, found %d", r.Removed)
+	}
+	if r.LockHeld <= 0 || r.LockHeld > r.Duration {
+		t.Errorf("expected the lock to be held between 0 and %v, found %v", r.Duration, r.LockHeld)
+	}
+
+	t.Run("stops when the context is Done", func(t *testing.T) {
+		s.SetWithTimeout(ctx, "expired", value("1"), -time.Second)
+
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+		if r, err := s.Purge(ctx); err != context.Canceled || r.Removed != 0 {
+			t.Errorf("expected (0, %v), found (%d, %v)", context.Canceled, r.Removed, err)
+		}
+	})
+}
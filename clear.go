@@ -0,0 +1,42 @@
+package mem
+
+import "context"
+
+// Clear removes all the entries, under a single acquisition of the write
+// lock. The options of the Store, including its background cleanup, are
+// kept.
+// Err is non-nil if the context is Done.
+func (s *Store) Clear(ctx context.Context) error {
+	_, err := s.deleteWhere(ctx, func(string, entry) (bool, error) { return true, nil })
+	return err
+}
+
+// ClearExpiredOnly removes all the expired entries, under a single
+// acquisition of the write lock, regardless of the grace period set
+// WithStaleWhileRevalidate. Unlike Cleanup, it does not stop at the
+// deadline of the context once started.
+// Err is non-nil if the context is Done.
+func (s *Store) ClearExpiredOnly(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	s.mu.Lock()
+	defer s.unlock()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	now := s.clock.Now().UnixNano()
+	for {
+		k, ok := s.exp.due(now)
+		if !ok {
+			return nil
+		}
+		s.remove(k, Expired)
+	}
+}
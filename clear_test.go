@@ -0,0 +1,50 @@
+package mem_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gokv/mem"
+)
+
+func TestClear(t *testing.T) {
+	ctx := context.Background()
+
+	s := mem.New()
+	defer s.Close()
+
+	s.Set(ctx, "a", String(`"a"`))
+	s.Set(ctx, "b", String(`"b"`))
+
+	if err := s.Clear(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n, _ := s.Len(ctx); n != 0 {
+		t.Errorf("expected no entries, found %d", n)
+	}
+
+	if err := s.Set(ctx, "c", String(`"c"`)); err != nil {
+		t.Errorf("expected the Store to be usable, found %v", err)
+	}
+}
+
+func TestClearExpiredOnly(t *testing.T) {
+	ctx := context.Background()
+
+	s := mem.New(mem.WithCleanupInterval(0))
+	defer s.Close()
+
+	s.Set(ctx, "permanent", String(`"a"`))
+	s.SetWithTimeout(ctx, "volatile", String(`"b"`), time.Minute)
+	s.SetWithTimeout(ctx, "short", String(`"c"`), time.Millisecond)
+
+	time.Sleep(5 * time.Millisecond)
+
+	if err := s.ClearExpiredOnly(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, n, _ := s.Size(ctx); n != 2 {
+		t.Errorf("expected 2 entries, found %d", n)
+	}
+}
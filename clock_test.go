@@ -0,0 +1,94 @@
+package mem
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is a Clock advanced manually.
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []fakeTimer
+}
+
+type fakeTimer struct {
+	at time.Time
+	ch chan time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Unix(1e9, 0)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ch := make(chan time.Time, 1)
+	c.waiters = append(c.waiters, fakeTimer{at: c.now.Add(d), ch: ch})
+	return ch
+}
+
+// Advance moves the clock forward by d, firing the timers due.
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+	waiters := c.waiters[:0]
+	for _, w := range c.waiters {
+		if w.at.After(c.now) {
+			waiters = append(waiters, w)
+			continue
+		}
+		w.ch <- c.now
+	}
+	c.waiters = waiters
+}
+
+func TestWithClock(t *testing.T) {
+	ctx := context.Background()
+
+	clock := newFakeClock()
+	s := New(WithClock(clock), WithCleanupInterval(0))
+	defer s.Close()
+
+	s.SetWithTimeout(ctx, "key", value("wazzup"), time.Hour)
+
+	var v value
+	if ok, _ := s.Get(ctx, "key", &v); !ok {
+		t.Error("expected the value to be present")
+	}
+	if ttl, _, _ := s.TTL(ctx, "key"); ttl != time.Hour {
+		t.Errorf("expected a TTL of %v, found %v", time.Hour, ttl)
+	}
+
+	clock.Advance(time.Hour + time.Nanosecond)
+	if ok, _ := s.Get(ctx, "key", &v); ok {
+		t.Error("expected the value to be expired")
+	}
+}
+
+func TestTieredClock(t *testing.T) {
+	ctx := context.Background()
+
+	clock := newFakeClock()
+	l1 := New(WithClock(clock), WithCleanupInterval(0))
+	l2 := New(WithClock(clock), WithCleanupInterval(0))
+	tiered := NewTiered(l1, l2)
+	defer tiered.Close()
+
+	tiered.SetWithTimeout(ctx, "key", value(`"v"`), time.Hour)
+	if ttl, _, _ := l1.TTL(ctx, "key"); ttl != time.Hour {
+		t.Errorf("expected a TTL of %v, found %v", time.Hour, ttl)
+	}
+}
@@ -0,0 +1,279 @@
+// Command memdump inspects and edits the snapshots written by
+// mem.Store.SaveTo, such as the files of mem.WithAutoSnapshot.
+//
+// Usage:
+//
+//	memdump [-key hex] inspect FILE
+//	memdump [-key hex] diff OLD NEW
+//	memdump [-key hex] grep REGEXP FILE
+//	memdump [-key hex] prune [-match GLOB] FILE
+//
+// Inspect lists the valid entries with their size and remaining lifetime.
+// Diff lists the keys added (+), removed (-) and changed (~) from OLD to
+// NEW. Grep lists the keys whose key or value matches REGEXP. Prune rewrites
+// FILE without its expired entries, and without the keys matching GLOB if
+// set.
+// The -key flag is the hex-encoded key of snapshots written WithEncryption.
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gokv/mem"
+)
+
+var errUsage = errors.New("usage: memdump [-key hex] inspect FILE | diff OLD NEW | grep REGEXP FILE | prune [-match GLOB] FILE")
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "memdump:", err)
+		os.Exit(2)
+	}
+}
+
+// run runs the command line args, writing the output to w.
+func run(args []string, w io.Writer) error {
+	flags := flag.NewFlagSet("memdump", flag.ContinueOnError)
+	key := flags.String("key", "", "hex-encoded encryption key")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	args = flags.Args()
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	var opts []mem.Option
+	if *key != "" {
+		k, err := hex.DecodeString(*key)
+		if err != nil {
+			return fmt.Errorf("invalid -key: %v", err)
+		}
+		if n := len(k); n != 16 && n != 24 && n != 32 {
+			return fmt.Errorf("invalid -key: %d bytes, expected 16, 24 or 32", n)
+		}
+		opts = append(opts, mem.WithEncryption(k))
+	}
+
+	ctx := context.Background()
+	switch cmd, args := args[0], args[1:]; cmd {
+	case "inspect":
+		if len(args) != 1 {
+			return errUsage
+		}
+		return inspect(ctx, w, args[0], opts)
+
+	case "diff":
+		if len(args) != 2 {
+			return errUsage
+		}
+		return diff(ctx, w, args[0], args[1], opts)
+
+	case "grep":
+		if len(args) != 2 {
+			return errUsage
+		}
+		re, err := regexp.Compile(args[0])
+		if err != nil {
+			return err
+		}
+		return grep(ctx, w, re, args[1], opts)
+
+	case "prune":
+		pruneFlags := flag.NewFlagSet("prune", flag.ContinueOnError)
+		match := pruneFlags.String("match", "", "also delete the keys matching this glob pattern")
+		if err := pruneFlags.Parse(args); err != nil {
+			return err
+		}
+		if pruneFlags.NArg() != 1 {
+			return errUsage
+		}
+		return prune(ctx, w, pruneFlags.Arg(0), *match, opts)
+
+	default:
+		return errUsage
+	}
+}
+
+// load returns a Store holding the valid entries of the snapshot at path.
+func load(ctx context.Context, path string, opts []mem.Option) (*mem.Store, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	s := mem.New(append([]mem.Option{mem.WithCleanupInterval(0)}, opts...)...)
+	if err := s.LoadFrom(ctx, f); err != nil {
+		s.Close()
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return s, nil
+}
+
+// sortedKeys returns the keys of s in order.
+func sortedKeys(ctx context.Context, s *mem.Store) ([]string, error) {
+	keys, err := s.Keys(ctx)
+	sort.Strings(keys)
+	return keys, err
+}
+
+func inspect(ctx context.Context, w io.Writer, path string, opts []mem.Option) error {
+	s, err := load(ctx, path, opts)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	keys, err := sortedKeys(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "KEY\tBYTES\tTTL")
+	var total int
+	for _, k := range keys {
+		b, _, err := s.GetBytes(ctx, k)
+		if err != nil {
+			return fmt.Errorf("%s: %v", k, err)
+		}
+		total += len(b)
+
+		ttl := "-"
+		if d, ok, _ := s.TTL(ctx, k); ok {
+			ttl = d.Round(time.Second).String()
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\n", k, len(b), ttl)
+	}
+	fmt.Fprintf(tw, "%d entries\t%d\t\n", len(keys), total)
+	return tw.Flush()
+}
+
+func diff(ctx context.Context, w io.Writer, oldPath, newPath string, opts []mem.Option) error {
+	old, err := load(ctx, oldPath, opts)
+	if err != nil {
+		return err
+	}
+	defer old.Close()
+
+	s, err := load(ctx, newPath, opts)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	oldKeys, err := sortedKeys(ctx, old)
+	if err != nil {
+		return err
+	}
+	keys, err := sortedKeys(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	for i, j := 0, 0; i < len(oldKeys) || j < len(keys); {
+		switch {
+		case j == len(keys) || (i < len(oldKeys) && oldKeys[i] < keys[j]):
+			fmt.Fprintln(w, "-", oldKeys[i])
+			i++
+		case i == len(oldKeys) || keys[j] < oldKeys[i]:
+			fmt.Fprintln(w, "+", keys[j])
+			j++
+		default:
+			a, _, err := old.GetBytes(ctx, oldKeys[i])
+			if err != nil {
+				return err
+			}
+			b, _, err := s.GetBytes(ctx, keys[j])
+			if err != nil {
+				return err
+			}
+			if !bytes.Equal(a, b) {
+				fmt.Fprintln(w, "~", keys[j])
+			}
+			i++
+			j++
+		}
+	}
+	return nil
+}
+
+func grep(ctx context.Context, w io.Writer, re *regexp.Regexp, path string, opts []mem.Option) error {
+	s, err := load(ctx, path, opts)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	keys, err := sortedKeys(ctx, s)
+	if err != nil {
+		return err
+	}
+	for _, k := range keys {
+		b, _, err := s.GetBytes(ctx, k)
+		if err != nil {
+			return fmt.Errorf("%s: %v", k, err)
+		}
+		if re.MatchString(k) || re.Match(b) {
+			fmt.Fprintln(w, k)
+		}
+	}
+	return nil
+}
+
+func prune(ctx context.Context, w io.Writer, path, match string, opts []mem.Option) error {
+	s, err := load(ctx, path, opts)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	if match != "" {
+		keys, err := s.KeysMatching(ctx, match)
+		if err != nil {
+			return err
+		}
+		for _, k := range keys {
+			if _, err := s.Delete(ctx, k); err != nil {
+				return err
+			}
+		}
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name()) // no-op after a successful rename
+
+	if err := s.SaveTo(ctx, f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		return err
+	}
+
+	n, err := s.Len(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "%d entries kept\n", n)
+	return nil
+}
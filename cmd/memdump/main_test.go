@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gokv/mem"
+)
+
+type String string
+
+func (s String) MarshalJSON() ([]byte, error) { return []byte(s), nil }
+
+// dump writes a snapshot of the given values, and returns its path.
+func dump(t *testing.T, values map[string]String) string {
+	t.Helper()
+	ctx := context.Background()
+
+	s := mem.New()
+	defer s.Close()
+	for k, v := range values {
+		s.Set(ctx, k, v)
+	}
+	s.SetWithTimeout(ctx, "expired", String(`"gone"`), time.Millisecond)
+	time.Sleep(2 * time.Millisecond)
+
+	path := filepath.Join(t.TempDir(), "dump")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := s.SaveTo(ctx, f); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRun(t *testing.T) {
+	old := dump(t, map[string]String{"a": `1`, "b": `"x"`, "c": `3`})
+	path := dump(t, map[string]String{"b": `"y"`, "c": `3`, "d": `4`})
+
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"diff", old, path}, "- a\n~ b\n+ d\n"},
+		{[]string{"grep", `^d$|"y"`, path}, "b\nd\n"},
+		{[]string{"inspect", old}, "KEY        BYTES  TTL\na          1      -\nb          3      -\nc          1      -\n3 entries  5      \n"},
+		{[]string{"prune", "-match", "b*", path}, "2 entries kept\n"},
+	} {
+		var buf bytes.Buffer
+		if err := run(tc.args, &buf); err != nil {
+			t.Errorf("%v: unexpected error: %v", tc.args, err)
+			continue
+		}
+		if found := buf.String(); found != tc.want {
+			t.Errorf("%v: expected %q, found %q", tc.args, tc.want, found)
+		}
+	}
+
+	if err := run([]string{"unknown"}, new(bytes.Buffer)); err != errUsage {
+		t.Errorf("expected error %v, found %v", errUsage, err)
+	}
+}
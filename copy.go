@@ -0,0 +1,54 @@
+package mem
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/gokv/store"
+)
+
+// CopyTo writes all the valid entries to dst, in key order, preserving their
+// keys and deadlines. The entries are copied under the read lock, so that
+// dst is written to without holding it. Sliding expirations are copied as
+// their current deadline.
+// Err is non-nil if the context is Done, if a value cannot be decrypted, or
+// if writing to dst fails.
+func (s *Store) CopyTo(ctx context.Context, dst store.Store) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	m := s.Snapshot().m
+
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		e := m[k]
+		b, err := s.value(e)
+		if err != nil {
+			return err
+		}
+		if e.validTo == 0 {
+			err = dst.Set(ctx, k, raw(b))
+		} else {
+			err = dst.SetWithDeadline(ctx, k, raw(b), time.Unix(0, e.validTo))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package mem_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gokv/mem"
+)
+
+func TestCopyTo(t *testing.T) {
+	ctx := context.Background()
+
+	src := mem.New(mem.WithEncryption(make([]byte, 32)))
+	defer src.Close()
+
+	src.Set(ctx, "permanent", String(`"a"`))
+	src.SetWithTimeout(ctx, "volatile", String(`"b"`), time.Minute)
+
+	dst := mem.New()
+	defer dst.Close()
+
+	if err := src.CopyTo(ctx, dst); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var v String
+	if ok, _ := dst.Get(ctx, "permanent", &v); !ok || v != `"a"` {
+		t.Errorf("expected %q, found %q", `"a"`, v)
+	}
+	if _, ok, _ := dst.TTL(ctx, "permanent"); ok {
+		t.Error("expected no deadline")
+	}
+	if ttl, ok, _ := dst.TTL(ctx, "volatile"); !ok || ttl <= 0 || ttl > time.Minute {
+		t.Errorf("expected the deadline to be preserved, found %v", ttl)
+	}
+
+	t.Run("stops on a Done context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+		if err := src.CopyTo(ctx, dst); err != context.Canceled {
+			t.Errorf("expected error %v, found %v", context.Canceled, err)
+		}
+	})
+}
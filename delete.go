@@ -0,0 +1,73 @@
+package mem
+
+import (
+	"context"
+	"strings"
+)
+
+// DeleteByPrefix removes all the entries whose key starts with prefix, under
+// a single acquisition of the write lock, and returns the number of valid
+// entries removed.
+// Err is non-nil if the context is Done.
+func (s *Store) DeleteByPrefix(ctx context.Context, prefix string) (int, error) {
+	return s.deleteWhere(ctx, func(k string, _ entry) (bool, error) {
+		return strings.HasPrefix(k, prefix), nil
+	})
+}
+
+// DeleteWhere removes the valid entries for which pred returns true, under a
+// single acquisition of the write lock, and returns their number. The data
+// passed to pred must not be modified, and pred must not call the Store.
+// Err is non-nil if the context is Done or if a value cannot be decrypted,
+// in which case the entries already matched are removed nonetheless.
+func (s *Store) DeleteWhere(ctx context.Context, pred func(k string, data []byte) bool) (int, error) {
+	return s.deleteWhere(ctx, func(k string, e entry) (bool, error) {
+		if !e.validAt(s.clock.Now()) {
+			return false, nil
+		}
+		data, err := s.value(e)
+		if err != nil {
+			return false, err
+		}
+		return pred(k, data), nil
+	})
+}
+
+// deleteWhere deletes the entries satisfying match, under a single
+// acquisition of the write lock, and returns the number of valid entries
+// deleted.
+func (s *Store) deleteWhere(ctx context.Context, match func(k string, e entry) (bool, error)) (int, error) {
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	default:
+	}
+
+	s.mu.Lock()
+	defer s.unlock()
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	default:
+	}
+	if s.frozen {
+		return 0, ErrReadOnly
+	}
+
+	now := s.clock.Now()
+	var n int
+	for k, e := range s.m {
+		ok, err := match(k, e)
+		if err != nil {
+			return n, err
+		}
+		if !ok {
+			continue
+		}
+		if e.validAt(now) {
+			n++
+		}
+		s.remove(k, Deleted)
+	}
+	return n, nil
+}
@@ -0,0 +1,59 @@
+package mem_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/gokv/mem"
+)
+
+func TestDeleteByPrefix(t *testing.T) {
+	ctx := context.Background()
+
+	s := mem.New()
+	defer s.Close()
+
+	s.Set(ctx, "user:1:name", String(`"alice"`))
+	s.Set(ctx, "user:1:email", String(`"alice@example.com"`))
+	s.Set(ctx, "user:2:name", String(`"bob"`))
+
+	n, err := s.DeleteByPrefix(ctx, "user:1:")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("expected 2 removals, found %d", n)
+	}
+
+	keys, _ := s.Keys(ctx)
+	if len(keys) != 1 || keys[0] != "user:2:name" {
+		t.Errorf("expected %q, found %q", []string{"user:2:name"}, keys)
+	}
+}
+
+func TestDeleteWhere(t *testing.T) {
+	ctx := context.Background()
+
+	s := mem.New()
+	defer s.Close()
+
+	s.Set(ctx, "a", String(`{"schema":1}`))
+	s.Set(ctx, "b", String(`{"schema":2}`))
+	s.Set(ctx, "c", String(`{"schema":1}`))
+
+	n, err := s.DeleteWhere(ctx, func(k string, data []byte) bool {
+		return bytes.Contains(data, []byte(`"schema":1`))
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("expected 2 removals, found %d", n)
+	}
+
+	keys, _ := s.Keys(ctx)
+	if len(keys) != 1 || keys[0] != "b" {
+		t.Errorf("expected %q, found %q", []string{"b"}, keys)
+	}
+}
@@ -0,0 +1,52 @@
+package mem
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"io"
+)
+
+// ErrDecrypt is returned when a stored value cannot be decrypted.
+var ErrDecrypt = errors.New("the value could not be decrypted")
+
+// seal encrypts b if encryption is set, prefixing the result with a random
+// nonce. Otherwise it returns b unchanged.
+func (s *Store) seal(b []byte) []byte {
+	if s.aead == nil {
+		return b
+	}
+
+	nonce := make([]byte, s.aead.NonceSize(), s.aead.NonceSize()+len(b)+s.aead.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		panic("mem: reading random nonce: " + err.Error())
+	}
+	return s.aead.Seal(nonce, nonce, b, nil)
+}
+
+// value returns the plaintext value of e.
+func (s *Store) value(e entry) ([]byte, error) {
+	if s.aead == nil {
+		return e.data, nil
+	}
+
+	n := s.aead.NonceSize()
+	if len(e.data) < n {
+		return nil, ErrDecrypt
+	}
+	b, err := s.aead.Open(nil, e.data[:n], e.data[n:], nil)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return b, nil
+}
+
+// newAEAD returns an AES-GCM cipher for the given key.
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,52 @@
+package mem
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestWithEncryption(t *testing.T) {
+	s := New(WithEncryption(bytes.Repeat([]byte{42}, 32)))
+	defer s.Close()
+
+	ctx := context.Background()
+	secret := value(`"session token"`)
+
+	if err := s.Set(ctx, "key", secret); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	s.mu.RLock()
+	stored := s.m["key"].data
+	s.mu.RUnlock()
+	if bytes.Contains(stored, []byte("session token")) {
+		t.Error("expected the value to be stored encrypted")
+	}
+
+	var v value
+	if ok, err := s.Get(ctx, "key", &v); err != nil || !ok {
+		t.Fatalf("expected a hit, found %v (err: %v)", ok, err)
+	}
+	if v != secret {
+		t.Errorf("expected %q, found %q", secret, v)
+	}
+
+	ok, err := s.CompareAndSwap(ctx, "key", secret, value(`"rotated"`))
+	if err != nil || !ok {
+		t.Errorf("expected a swap on the plaintext value, found %v (err: %v)", ok, err)
+	}
+
+	t.Run("detects tampering", func(t *testing.T) {
+		s.mu.Lock()
+		e := s.m["key"]
+		e.data = append([]byte{}, e.data...)
+		e.data[len(e.data)-1] ^= 1
+		s.m["key"] = e
+		s.mu.Unlock()
+
+		if _, err := s.Get(ctx, "key", &v); err != ErrDecrypt {
+			t.Errorf("expected error %v, found %v", ErrDecrypt, err)
+		}
+	})
+}
@@ -0,0 +1,40 @@
+package mem
+
+// EvictionReason is the reason why an entry left the Store.
+type EvictionReason int
+
+const (
+	// Expired entries reached their deadline.
+	Expired EvictionReason = iota + 1
+
+	// Evicted entries were removed to make room for others.
+	Evicted
+
+	// Deleted entries were explicitly removed.
+	Deleted
+
+	// Replaced entries were overwritten with a new value.
+	Replaced
+)
+
+func (r EvictionReason) String() string {
+	switch r {
+	case Expired:
+		return "expired"
+	case Evicted:
+		return "evicted"
+	case Deleted:
+		return "deleted"
+	case Replaced:
+		return "replaced"
+	default:
+		return "unknown"
+	}
+}
+
+// removal is an entry that left the Store, pending notification.
+type removal struct {
+	key    string
+	data   []byte
+	reason EvictionReason
+}
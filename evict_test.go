@@ -0,0 +1,166 @@
+package mem_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gokv/mem"
+)
+
+func TestWithOnEvict(t *testing.T) {
+	type eviction struct {
+		key    string
+		data   string
+		reason mem.EvictionReason
+	}
+
+	var evictions []eviction
+	s := mem.New(mem.WithCleanupInterval(0), mem.WithOnEvict(func(k string, data []byte, reason mem.EvictionReason) {
+		evictions = append(evictions, eviction{k, string(data), reason})
+	}))
+	defer s.Close()
+
+	ctx := context.Background()
+	s.Set(ctx, "key", String("1"))
+	s.Set(ctx, "key", String("2"))
+	s.Delete(ctx, "key")
+	s.Delete(ctx, "unset key")
+	s.SetWithTimeout(ctx, "volatile", String("3"), -time.Second)
+	s.Cleanup(ctx)
+
+	want := []eviction{
+		{"key", "1", mem.Replaced},
+		{"key", "2", mem.Deleted},
+		{"volatile", "3", mem.Expired},
+	}
+	if !reflect.DeepEqual(evictions, want) {
+		t.Errorf("expected %v, found %v", want, evictions)
+	}
+}
+
+func TestWithMaxEntries(t *testing.T) {
+	var evicted []string
+	s := mem.New(mem.WithMaxEntries(2), mem.WithOnEvict(func(k string, _ []byte, reason mem.EvictionReason) {
+		if reason == mem.Evicted {
+			evicted = append(evicted, k)
+		}
+	}))
+	defer s.Close()
+
+	ctx := context.Background()
+	s.Set(ctx, "a", String("1"))
+	s.Set(ctx, "b", String("2"))
+
+	var v String
+	s.Get(ctx, "a", &v) // b is now the least recently used
+
+	s.Set(ctx, "c", String("3"))
+
+	if want := []string{"b"}; !reflect.DeepEqual(evicted, want) {
+		t.Errorf("expected %q to be evicted, found %q", want, evicted)
+	}
+	for k, want := range map[string]bool{"a": true, "b": false, "c": true} {
+		if ok, _ := s.Exists(ctx, k); ok != want {
+			t.Errorf("expected presence of %q to be %v, found %v", k, want, ok)
+		}
+	}
+}
+
+func TestWithEvictionPolicy(t *testing.T) {
+	for _, tc := range [...]struct {
+		name   string
+		policy mem.EvictionPolicy
+		want   []string
+	}{
+		{"lru", mem.NewLRU(), []string{"b", "a"}},
+		{"fifo", mem.NewFIFO(), []string{"a", "b"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var evicted []string
+			s := mem.New(
+				mem.WithMaxEntries(2),
+				mem.WithEvictionPolicy(tc.policy),
+				mem.WithOnEvict(func(k string, _ []byte, reason mem.EvictionReason) {
+					if reason == mem.Evicted {
+						evicted = append(evicted, k)
+					}
+				}),
+			)
+			defer s.Close()
+
+			ctx := context.Background()
+			s.Set(ctx, "a", String("1"))
+			s.Set(ctx, "b", String("2"))
+
+			var v String
+			s.Get(ctx, "a", &v)
+
+			s.Set(ctx, "c", String("3"))
+			s.Set(ctx, "d", String("4"))
+
+			if !reflect.DeepEqual(evicted, tc.want) {
+				t.Errorf("expected %q to be evicted, found %q", tc.want, evicted)
+			}
+		})
+	}
+}
+
+func TestWithMaxBytes(t *testing.T) {
+	t.Run("evicts over budget", func(t *testing.T) {
+		s := mem.New(mem.WithMaxBytes(10))
+		defer s.Close()
+
+		ctx := context.Background()
+		s.Set(ctx, "a", String("1234")) // costs 5
+		s.Set(ctx, "b", String("1234"))
+		s.Set(ctx, "a", String("12")) // replacing frees 2 bytes
+		s.Set(ctx, "c", String("1"))  // 3 + 5 + 2 = 10: fits
+
+		for k, want := range map[string]bool{"a": true, "b": true, "c": true} {
+			if ok, _ := s.Exists(ctx, k); ok != want {
+				t.Errorf("expected presence of %q to be %v, found %v", k, want, ok)
+			}
+		}
+
+		s.Set(ctx, "d", String("1")) // over budget: b is the least recently used
+
+		for k, want := range map[string]bool{"a": true, "b": false, "c": true, "d": true} {
+			if ok, _ := s.Exists(ctx, k); ok != want {
+				t.Errorf("expected presence of %q to be %v, found %v", k, want, ok)
+			}
+		}
+	})
+
+	t.Run("uses the cost function", func(t *testing.T) {
+		s := mem.New(mem.WithMaxBytes(2), mem.WithCostFunc(func(string, []byte) int64 { return 1 }))
+		defer s.Close()
+
+		ctx := context.Background()
+		for _, k := range []string{"a", "b", "c"} {
+			s.Set(ctx, k, String("a long value that does not matter"))
+		}
+
+		if n, _ := s.Len(ctx); n != 2 {
+			t.Errorf("expected 2 entries, found %d", n)
+		}
+	})
+}
+
+func TestEvictionUnknownVictim(t *testing.T) {
+	ctx := context.Background()
+
+	p := mem.NewFIFO()
+	p.OnSet("unknown")
+
+	s := mem.New(mem.WithMaxEntries(1), mem.WithEvictionPolicy(p))
+	defer s.Close()
+
+	s.Set(ctx, "a", String("1"))
+	s.Set(ctx, "b", String("2"))
+
+	if n, _ := s.Len(ctx); n != 1 {
+		t.Errorf("expected 1 entry, found %d", n)
+	}
+}
@@ -0,0 +1,77 @@
+package mem
+
+import "container/heap"
+
+// expiry schedules the removal of the entry corresponding to key.
+type expiry struct {
+	key     string
+	validTo int64
+	index   int // position in the heap
+}
+
+// expiries is a min-heap of the deadlines of the entries, with at most one
+// item per key. It lets Cleanup visit only the entries that are due.
+type expiries struct {
+	items []*expiry
+	byKey map[string]*expiry
+}
+
+func newExpiries() *expiries {
+	return &expiries{byKey: make(map[string]*expiry)}
+}
+
+func (h *expiries) Len() int           { return len(h.items) }
+func (h *expiries) Less(i, j int) bool { return h.items[i].validTo < h.items[j].validTo }
+
+func (h *expiries) Swap(i, j int) {
+	h.items[i], h.items[j] = h.items[j], h.items[i]
+	h.items[i].index = i
+	h.items[j].index = j
+}
+
+func (h *expiries) Push(x interface{}) {
+	item := x.(*expiry)
+	item.index = len(h.items)
+	h.items = append(h.items, item)
+}
+
+func (h *expiries) Pop() interface{} {
+	n := len(h.items) - 1
+	item := h.items[n]
+	h.items[n] = nil
+	h.items = h.items[:n]
+	return item
+}
+
+// set schedules the key to expire at validTo, replacing any previous
+// schedule. A zero validTo unschedules the key.
+func (h *expiries) set(k string, validTo int64) {
+	item, ok := h.byKey[k]
+	switch {
+	case validTo == 0:
+		h.unset(k)
+	case ok:
+		item.validTo = validTo
+		heap.Fix(h, item.index)
+	default:
+		item = &expiry{key: k, validTo: validTo}
+		h.byKey[k] = item
+		heap.Push(h, item)
+	}
+}
+
+// unset unschedules the key, if scheduled.
+func (h *expiries) unset(k string) {
+	if item, ok := h.byKey[k]; ok {
+		heap.Remove(h, item.index)
+		delete(h.byKey, k)
+	}
+}
+
+// due returns the key with the earliest deadline, if it is before t.
+func (h *expiries) due(t int64) (string, bool) {
+	if len(h.items) == 0 || h.items[0].validTo >= t {
+		return "", false
+	}
+	return h.items[0].key, true
+}
@@ -0,0 +1,63 @@
+package mem
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestExpiries(t *testing.T) {
+	h := newExpiries()
+	h.set("c", 30)
+	h.set("a", 10)
+	h.set("b", 20)
+	h.set("d", 40)
+
+	h.set("c", 5) // reschedule
+	h.set("d", 0) // unschedule
+	h.unset("b")  // unschedule
+
+	var keys []string
+	for {
+		k, ok := h.due(100)
+		if !ok {
+			break
+		}
+		keys = append(keys, k)
+		h.unset(k)
+	}
+
+	if len(keys) != 2 || keys[0] != "c" || keys[1] != "a" {
+		t.Errorf("expected [c a], found %q", keys)
+	}
+	if h.Len() != 0 || len(h.byKey) != 0 {
+		t.Errorf("expected an empty heap, found %d items and %d keys", h.Len(), len(h.byKey))
+	}
+}
+
+func TestCleanupDueOnly(t *testing.T) {
+	s := New(WithCleanupInterval(0))
+	defer s.Close()
+
+	ctx := context.Background()
+	s.SetWithTimeout(ctx, "expired", value("1"), -time.Second)
+	s.SetWithTimeout(ctx, "volatile", value("2"), time.Minute)
+	s.SetWithTimeout(ctx, "persisted", value("3"), -time.Second)
+	s.mu.Lock()
+	e := s.m["persisted"]
+	e.validTo = 0
+	s.put("persisted", e)
+	s.mu.Unlock()
+	s.Set(ctx, "permanent", value("4"))
+
+	s.Cleanup(ctx)
+
+	for k, want := range map[string]bool{"expired": false, "volatile": true, "persisted": true, "permanent": true} {
+		if _, ok := s.m[k]; ok != want {
+			t.Errorf("expected presence of %q to be %v, found %v", k, want, ok)
+		}
+	}
+	if s.exp.Len() != 1 {
+		t.Errorf("expected 1 scheduled expiry, found %d", s.exp.Len())
+	}
+}
@@ -0,0 +1,109 @@
+package mem
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// exported is an entry in the document written by ExportJSON.
+type exported struct {
+	Value     json.RawMessage `json:"value"`
+	ExpiresAt *time.Time      `json:"expiresAt,omitempty"`
+}
+
+// ExportJSON writes all the valid entries to w as an indented JSON object,
+// mapping each key to its value and, if any, its deadline:
+//
+//	{"key": {"value": ..., "expiresAt": "2006-01-02T15:04:05Z"}}
+//
+// Values are decrypted, and must be JSON. Sliding expirations are written
+// as their current deadline.
+// Err is non-nil if the context is Done, if a value cannot be decrypted, or
+// if writing fails.
+func (s *Store) ExportJSON(ctx context.Context, w io.Writer) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	m := s.Snapshot().m
+
+	doc := make(map[string]exported, len(m))
+	for k, e := range m {
+		b, err := s.value(e)
+		if err != nil {
+			return err
+		}
+		x := exported{Value: b}
+		if e.validTo != 0 {
+			t := time.Unix(0, e.validTo).UTC()
+			x.ExpiresAt = &t
+		}
+		doc[k] = x
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+// ImportJSON reads a document in the format written by ExportJSON from r,
+// and assigns its entries to their keys, possibly overwriting. Entries
+// without a deadline get the default TTL, if set; entries already expired
+// are skipped. Nothing is written unless the whole document is valid.
+// Err is non-nil if the context is Done, or if r is not a valid document.
+func (s *Store) ImportJSON(ctx context.Context, r io.Reader) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	var doc map[string]exported
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return err
+	}
+	for k, x := range doc {
+		if err := s.validate(k, x.Value); err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.unlock()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+	if s.frozen {
+		return ErrReadOnly
+	}
+
+	now := s.clock.Now()
+	writes := make(batch, len(doc))
+	for k, x := range doc {
+		if x.ExpiresAt == nil {
+			e := s.newEntry(k, x.Value)
+			writes[k] = &e
+			continue
+		}
+		if x.ExpiresAt.After(now) {
+			writes[k] = &entry{data: s.seal(x.Value), validTo: x.ExpiresAt.UnixNano()}
+		}
+	}
+	if err := s.admitAll(writes); err != nil {
+		return err
+	}
+	writes.apply(s)
+	return nil
+}
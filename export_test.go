@@ -0,0 +1,79 @@
+package mem_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gokv/mem"
+)
+
+func TestExportJSON(t *testing.T) {
+	ctx := context.Background()
+
+	s := mem.New()
+	defer s.Close()
+
+	deadline := time.Date(2100, 1, 2, 3, 4, 5, 0, time.UTC)
+	s.Set(ctx, "a", String(`"x"`))
+	s.SetWithDeadline(ctx, "b", String(`{"n":1}`), deadline)
+
+	var buf bytes.Buffer
+	if err := s.ExportJSON(ctx, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `{
+  "a": {
+    "value": "x"
+  },
+  "b": {
+    "value": {
+      "n": 1
+    },
+    "expiresAt": "2100-01-02T03:04:05Z"
+  }
+}
+`
+	if found := buf.String(); found != want {
+		t.Errorf("expected %q, found %q", want, found)
+	}
+}
+
+func TestImportJSON(t *testing.T) {
+	ctx := context.Background()
+
+	s := mem.New()
+	defer s.Close()
+
+	doc := `{
+		"a": {"value": "x"},
+		"b": {"value": 2, "expiresAt": "2100-01-02T03:04:05Z"},
+		"old": {"value": 3, "expiresAt": "2000-01-01T00:00:00Z"}
+	}`
+	if err := s.ImportJSON(ctx, strings.NewReader(doc)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var v String
+	if ok, _ := s.Get(ctx, "a", &v); !ok || v != `"x"` {
+		t.Errorf("expected %q, found %q", `"x"`, v)
+	}
+	if _, ok, _ := s.TTL(ctx, "b"); !ok {
+		t.Error("expected the deadline to be imported")
+	}
+	if ok, _ := s.Exists(ctx, "old"); ok {
+		t.Error("expected the expired entry to be skipped")
+	}
+
+	t.Run("rejects invalid documents", func(t *testing.T) {
+		if err := s.ImportJSON(ctx, strings.NewReader(`{"c": {"value": }`)); err == nil {
+			t.Error("expected an error")
+		}
+		if ok, _ := s.Exists(ctx, "c"); ok {
+			t.Error("expected nothing to be written")
+		}
+	})
+}
@@ -0,0 +1,21 @@
+package mem
+
+import (
+	"context"
+	"expvar"
+)
+
+// PublishExpvar publishes the Stats of the Store, along with its
+// approximate size in bytes, as an expvar variable with the given name, so
+// that they are served on /debug/vars. The variable is computed on every
+// read.
+// Like expvar.Publish, PublishExpvar panics if the name is already in use.
+func (s *Store) PublishExpvar(name string) {
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		bytes, _, _ := s.Size(context.Background())
+		return struct {
+			Stats
+			Bytes int64
+		}{s.Stats(), bytes}
+	}))
+}
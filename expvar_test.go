@@ -0,0 +1,46 @@
+package mem_test
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"testing"
+
+	"github.com/gokv/mem"
+)
+
+// expvarRuns counts the runs of TestPublishExpvar.
+var expvarRuns int
+
+func TestPublishExpvar(t *testing.T) {
+	ctx := context.Background()
+
+	s := mem.New()
+	defer s.Close()
+
+	// expvar names are global to the process: tell apart the runs of
+	// go test -count.
+	expvarRuns++
+	name := fmt.Sprintf("%s_%d", t.Name(), expvarRuns)
+
+	s.PublishExpvar(name)
+	s.Set(ctx, "key", String(`"v"`))
+
+	v := expvar.Get(name)
+	if v == nil {
+		t.Fatal("expected the variable to be published")
+	}
+
+	var found struct {
+		Sets    uint64
+		Entries int
+		Bytes   int64
+	}
+	if err := json.Unmarshal([]byte(v.String()), &found); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if found.Sets != 1 || found.Entries != 1 || found.Bytes <= 0 {
+		t.Errorf("expected the current counters and size, found %+v", found)
+	}
+}
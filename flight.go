@@ -0,0 +1,91 @@
+package mem
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// flight is an in-flight call of a Loader.
+type flight struct {
+	done    chan struct{} // closed when the call returns
+	value   []byte
+	ttl     time.Duration
+	err     error
+	waiters int                // callers waiting for the result
+	cancel  context.CancelFunc // cancels the call
+}
+
+// flights deduplicates the concurrent Loader calls for the same key.
+type flights struct {
+	mu sync.Mutex
+	m  map[string]*flight
+}
+
+// do calls fn for k, unless a call for k is already in flight, in which case
+// it waits for its result, and fn is not called: flights are per key, not
+// per loader. The call carries the values of the context of the
+// first caller, but not its deadline: each caller stops waiting when its own
+// context is Done, and the call is canceled once no caller is waiting.
+func (fs *flights) do(ctx context.Context, k string, fn Loader) ([]byte, time.Duration, error) {
+	fs.mu.Lock()
+	f, ok := fs.m[k]
+	if !ok {
+		if fs.m == nil {
+			fs.m = make(map[string]*flight)
+		}
+		callCtx, cancel := context.WithCancel(detached{ctx})
+		f = &flight{done: make(chan struct{}), cancel: cancel}
+		fs.m[k] = f
+		go fs.call(callCtx, k, f, fn)
+	}
+	f.waiters++
+	fs.mu.Unlock()
+
+	select {
+	case <-f.done:
+		return f.value, f.ttl, f.err
+	case <-ctx.Done():
+		fs.mu.Lock()
+		f.waiters--
+		if f.waiters == 0 {
+			f.cancel()
+			fs.forget(k, f)
+		}
+		fs.mu.Unlock()
+		return nil, 0, ctx.Err()
+	}
+}
+
+// call runs fn for the flight f of k, and publishes its result.
+func (fs *flights) call(ctx context.Context, k string, f *flight, fn Loader) {
+	defer func() {
+		fs.mu.Lock()
+		fs.forget(k, f)
+		fs.mu.Unlock()
+		f.cancel()
+		close(f.done)
+	}()
+
+	f.value, f.ttl, f.err = fn(ctx, k)
+}
+
+// forget removes f from the flights in progress, unless a new flight for k
+// replaced it. The caller must hold fs.mu.
+func (fs *flights) forget(k string, f *flight) {
+	if fs.m[k] == f {
+		delete(fs.m, k)
+	}
+}
+
+// detached is a context carrying the values of its parent, but neither its
+// deadline nor its cancellation.
+type detached struct {
+	context.Context
+}
+
+func (detached) Deadline() (time.Time, bool) { return time.Time{}, false }
+
+func (detached) Done() <-chan struct{} { return nil }
+
+func (detached) Err() error { return nil }
@@ -0,0 +1,87 @@
+package mem
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestFlightsFirstCallerCanceled(t *testing.T) {
+	var fs flights
+
+	release := make(chan struct{})
+	load := func(ctx context.Context, k string) ([]byte, time.Duration, error) {
+		<-release
+		if err := ctx.Err(); err != nil {
+			return nil, 0, err
+		}
+		return []byte(`"loaded"`), time.Minute, nil
+	}
+
+	first, cancel := context.WithCancel(context.Background())
+	firstErr := make(chan error)
+	go func() {
+		_, _, err := fs.do(first, "k", load)
+		firstErr <- err
+	}()
+	waitWaiters(&fs, "k", 1)
+
+	type result struct {
+		value []byte
+		err   error
+	}
+	second := make(chan result)
+	go func() {
+		b, _, err := fs.do(context.Background(), "k", load)
+		second <- result{b, err}
+	}()
+	waitWaiters(&fs, "k", 2)
+
+	cancel()
+	if err := <-firstErr; err != context.Canceled {
+		t.Errorf("expected the first caller to stop with %v, found %v", context.Canceled, err)
+	}
+
+	close(release)
+	if r := <-second; r.err != nil || string(r.value) != `"loaded"` {
+		t.Errorf("expected %q, found %q (%v)", `"loaded"`, r.value, r.err)
+	}
+}
+
+func TestFlightsAllCallersCanceled(t *testing.T) {
+	var fs flights
+
+	canceled := make(chan struct{})
+	load := func(ctx context.Context, k string) ([]byte, time.Duration, error) {
+		<-ctx.Done()
+		close(canceled)
+		return nil, 0, ctx.Err()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go cancel()
+	if _, _, err := fs.do(ctx, "k", load); err != context.Canceled {
+		t.Errorf("expected error %v, found %v", context.Canceled, err)
+	}
+
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Error("expected the call to be canceled once no caller is waiting")
+	}
+}
+
+// waitWaiters waits until n callers wait for the flight of k.
+func waitWaiters(fs *flights, k string, n int) {
+	for {
+		fs.mu.Lock()
+		f, ok := fs.m[k]
+		ready := ok && f.waiters == n
+		fs.mu.Unlock()
+		if ready {
+			return
+		}
+		runtime.Gosched()
+	}
+}
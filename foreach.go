@@ -0,0 +1,44 @@
+package mem
+
+import "context"
+
+// ForEach calls fn with each valid entry, in no particular order, under the
+// read lock, until fn returns true or an error. The data passed to fn must
+// not be modified, and fn must not write to the Store: it would deadlock.
+// Use Iter to process the entries without holding the lock.
+// Err is non-nil if the context is Done, if a value cannot be decrypted, or
+// if fn returns an error, which is then returned as is.
+func (s *Store) ForEach(ctx context.Context, fn func(k string, data []byte) (stop bool, err error)) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := s.clock.Now()
+
+	for k, e := range s.m {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		if !e.validAt(now) {
+			continue
+		}
+
+		data, err := s.value(e)
+		if err != nil {
+			return err
+		}
+		stop, err := fn(k, data)
+		if err != nil || stop {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package mem_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gokv/mem"
+)
+
+func TestForEach(t *testing.T) {
+	ctx := context.Background()
+
+	s := mem.New()
+	defer s.Close()
+
+	for _, k := range []string{"a", "b", "c"} {
+		s.Set(ctx, k, String(`"`+k+`"`))
+	}
+
+	var visited int
+	err := s.ForEach(ctx, func(k string, data []byte) (bool, error) {
+		visited++
+		return false, nil
+	})
+	if err != nil || visited != 3 {
+		t.Errorf("expected 3 visits, found %d (%v)", visited, err)
+	}
+
+	t.Run("stops early", func(t *testing.T) {
+		var visited int
+		err := s.ForEach(ctx, func(k string, data []byte) (bool, error) {
+			visited++
+			return true, nil
+		})
+		if err != nil || visited != 1 {
+			t.Errorf("expected 1 visit, found %d (%v)", visited, err)
+		}
+	})
+
+	t.Run("returns the error of fn", func(t *testing.T) {
+		failure := errors.New("failure")
+		err := s.ForEach(ctx, func(k string, data []byte) (bool, error) {
+			return false, failure
+		})
+		if err != failure {
+			t.Errorf("expected error %v, found %v", failure, err)
+		}
+	})
+}
@@ -0,0 +1,25 @@
+package mem
+
+import "errors"
+
+// ErrReadOnly is returned by the methods writing to a frozen Store.
+var ErrReadOnly = errors.New("the store is read-only")
+
+// Freeze switches the Store to read-only: once the writes in progress
+// complete, all the methods writing to the Store return ErrReadOnly. Expired
+// entries are still removed, and values loaded WithLoader are returned
+// without being stored. A frozen Store cannot be unfrozen.
+func (s *Store) Freeze() {
+	s.mu.Lock()
+	defer s.unlock()
+
+	s.frozen = true
+}
+
+// Frozen reports whether Freeze was called.
+func (s *Store) Frozen() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.frozen
+}
@@ -0,0 +1,56 @@
+package mem_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gokv/mem"
+)
+
+func TestFreeze(t *testing.T) {
+	ctx := context.Background()
+
+	s := mem.New()
+	defer s.Close()
+
+	s.Set(ctx, "key", String(`"value"`))
+	s.Freeze()
+
+	if !s.Frozen() {
+		t.Error("expected the Store to be frozen")
+	}
+
+	var v String
+	if ok, err := s.Get(ctx, "key", &v); !ok || err != nil || v != `"value"` {
+		t.Errorf("expected %q, found %q (%v)", `"value"`, v, err)
+	}
+
+	for name, write := range map[string]func() error{
+		"Set": func() error { return s.Set(ctx, "key", String(`"other"`)) },
+		"SetWithTimeout": func() error {
+			return s.SetWithTimeout(ctx, "key", String(`"other"`), time.Minute)
+		},
+		"Add": func() error {
+			_, err := s.Add(ctx, String(`"other"`))
+			return err
+		},
+		"Delete": func() error {
+			_, err := s.Delete(ctx, "key")
+			return err
+		},
+		"Clear": func() error { return s.Clear(ctx) },
+		"Lock": func() error {
+			_, err := s.Lock(ctx, "lock", time.Minute)
+			return err
+		},
+	} {
+		if err := write(); err != mem.ErrReadOnly {
+			t.Errorf("%s: expected error %v, found %v", name, mem.ErrReadOnly, err)
+		}
+	}
+
+	if ok, _ := s.Get(ctx, "key", &v); !ok || v != `"value"` {
+		t.Errorf("expected %q, found %q", `"value"`, v)
+	}
+}
@@ -1 +1,3 @@
 module github.com/gokv/mem
+
+go 1.18
@@ -0,0 +1,106 @@
+// Package grpc serves a mem.Store over gRPC, with the Mem service defined in
+// mem.proto, so that programs in other languages can share it.
+package grpc
+
+//go:generate protoc -I.. --go_out=.. --go_opt=paths=source_relative --go-grpc_out=.. --go-grpc_opt=paths=source_relative grpc/mem.proto
+
+import (
+	"context"
+	"time"
+
+	"github.com/gokv/mem"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Server implements MemServer with a Store. Values are stored as is, and
+// are not required to be JSON.
+type Server struct {
+	UnimplementedMemServer
+
+	s *mem.Store
+}
+
+// NewServer returns a MemServer backed by s. Register it with
+// RegisterMemServer.
+func NewServer(s *mem.Store) *Server {
+	return &Server{s: s}
+}
+
+// raw is a value stored as is.
+type raw []byte
+
+func (r raw) MarshalJSON() ([]byte, error) { return r, nil }
+
+// Get returns the value of the key, if found.
+func (srv *Server) Get(ctx context.Context, req *GetRequest) (*GetResponse, error) {
+	b, ok, err := srv.s.GetBytes(ctx, req.GetKey())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &GetResponse{Found: ok, Value: b}, nil
+}
+
+// Set assigns the value to the key, possibly overwriting.
+func (srv *Server) Set(ctx context.Context, req *SetRequest) (*SetResponse, error) {
+	if err := srv.s.Set(ctx, req.GetKey(), raw(req.GetValue())); err != nil {
+		return nil, toStatus(err)
+	}
+	return &SetResponse{}, nil
+}
+
+// SetWithTTL assigns the value to the key, expiring after the TTL, which
+// must be positive.
+func (srv *Server) SetWithTTL(ctx context.Context, req *SetWithTTLRequest) (*SetResponse, error) {
+	if req.GetTtlMillis() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "ttl_millis must be positive")
+	}
+
+	ttl := time.Duration(req.GetTtlMillis()) * time.Millisecond
+	if err := srv.s.SetWithTimeout(ctx, req.GetKey(), raw(req.GetValue()), ttl); err != nil {
+		return nil, toStatus(err)
+	}
+	return &SetResponse{}, nil
+}
+
+// Delete removes the key, and reports whether it was present.
+func (srv *Server) Delete(ctx context.Context, req *DeleteRequest) (*DeleteResponse, error) {
+	ok, err := srv.s.Delete(ctx, req.GetKey())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &DeleteResponse{Deleted: ok}, nil
+}
+
+// Watch streams the events of the keys starting with the prefix, until the
+// client cancels. As with mem.Store.WatchPrefix, events are dropped if the
+// client does not keep up.
+func (srv *Server) Watch(req *WatchRequest, stream Mem_WatchServer) error {
+	events, err := srv.s.WatchPrefix(stream.Context(), req.GetPrefix())
+	if err != nil {
+		return toStatus(err)
+	}
+
+	for e := range events {
+		if err := stream.Send(&Event{
+			Op:           e.Op.String(),
+			Key:          e.Key,
+			TimeUnixNano: e.Time.UnixNano(),
+		}); err != nil {
+			return err
+		}
+	}
+	return toStatus(stream.Context().Err())
+}
+
+// toStatus converts the context errors to their gRPC status.
+func toStatus(err error) error {
+	switch err {
+	case context.Canceled:
+		return status.Error(codes.Canceled, err.Error())
+	case context.DeadlineExceeded:
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	default:
+		return err
+	}
+}
@@ -0,0 +1,84 @@
+package grpc_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gokv/mem"
+	memgrpc "github.com/gokv/mem/grpc"
+	"google.golang.org/grpc"
+)
+
+// watchStream is a Mem_WatchServer collecting the events sent.
+type watchStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	events chan *memgrpc.Event
+}
+
+func (s *watchStream) Context() context.Context { return s.ctx }
+
+func (s *watchStream) Send(e *memgrpc.Event) error {
+	s.events <- e
+	return nil
+}
+
+func TestServer(t *testing.T) {
+	ctx := context.Background()
+
+	s := mem.New()
+	defer s.Close()
+
+	srv := memgrpc.NewServer(s)
+
+	if _, err := srv.Set(ctx, &memgrpc.SetRequest{Key: "a", Value: []byte("not JSON")}); err != nil {
+		t.Fatalf("Set: unexpected error: %v", err)
+	}
+	res, err := srv.Get(ctx, &memgrpc.GetRequest{Key: "a"})
+	if err != nil || !res.GetFound() || string(res.GetValue()) != "not JSON" {
+		t.Errorf("Get: expected %q, found %q (%v, %v)", "not JSON", res.GetValue(), res.GetFound(), err)
+	}
+
+	if _, err := srv.SetWithTTL(ctx, &memgrpc.SetWithTTLRequest{Key: "b", Value: []byte("v"), TtlMillis: 60000}); err != nil {
+		t.Fatalf("SetWithTTL: unexpected error: %v", err)
+	}
+	if _, ok, _ := s.TTL(ctx, "b"); !ok {
+		t.Error("SetWithTTL: expected a deadline")
+	}
+	if _, err := srv.SetWithTTL(ctx, &memgrpc.SetWithTTLRequest{Key: "b"}); err == nil {
+		t.Error("SetWithTTL: expected an error without TTL")
+	}
+
+	if res, err := srv.Delete(ctx, &memgrpc.DeleteRequest{Key: "a"}); err != nil || !res.GetDeleted() {
+		t.Errorf("Delete: expected (true, nil), found (%v, %v)", res.GetDeleted(), err)
+	}
+	if res, _ := srv.Get(ctx, &memgrpc.GetRequest{Key: "a"}); res.GetFound() {
+		t.Error("Get: expected the key to be deleted")
+	}
+
+	t.Run("Watch", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		stream := &watchStream{ctx: ctx, events: make(chan *memgrpc.Event, 1)}
+
+		done := make(chan error)
+		go func() { done <- srv.Watch(&memgrpc.WatchRequest{Prefix: "w/"}, stream) }()
+
+		time.Sleep(10 * time.Millisecond)
+		srv.Set(ctx, &memgrpc.SetRequest{Key: "w/key", Value: []byte("1")})
+
+		select {
+		case e := <-stream.events:
+			if e.GetOp() != "set" || e.GetKey() != "w/key" {
+				t.Errorf("expected set %q, found %s %q", "w/key", e.GetOp(), e.GetKey())
+			}
+		case <-time.After(time.Second):
+			t.Fatal("expected an event")
+		}
+
+		cancel()
+		if err := <-done; err == nil {
+			t.Error("expected Watch to return the cancellation")
+		}
+	})
+}
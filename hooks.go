@@ -0,0 +1,19 @@
+package mem
+
+// mutation is a write or a deletion, pending notification to the hooks set
+// WithOnSet and WithOnDelete.
+type mutation struct {
+	key     string
+	data    []byte
+	deleted bool
+}
+
+// mutated records the write or the deletion of e, to be notified by unlock.
+// The caller must hold the write lock.
+func (s *Store) mutated(k string, e entry, deleted bool) {
+	if (deleted && s.onDelete == nil) || (!deleted && s.onSet == nil) {
+		return
+	}
+	data, _ := s.value(e)
+	s.mutations = append(s.mutations, mutation{key: k, data: data, deleted: deleted})
+}
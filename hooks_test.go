@@ -0,0 +1,49 @@
+package mem_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gokv/mem"
+)
+
+func TestHooks(t *testing.T) {
+	ctx := context.Background()
+
+	var log []string
+	s := mem.New(
+		mem.WithOnSet(func(k string, data []byte) {
+			log = append(log, "set "+k+" "+string(data))
+		}),
+		mem.WithOnDelete(func(k string, data []byte) {
+			log = append(log, "delete "+k+" "+string(data))
+		}),
+	)
+	defer s.Close()
+
+	s.Set(ctx, "a", String(`"1"`))
+	s.Set(ctx, "a", String(`"2"`))
+	s.Delete(ctx, "a")
+	s.Delete(ctx, "missing")
+	k, _ := s.Add(ctx, String(`"3"`))
+
+	want := []string{
+		`set a "1"`,
+		`set a "2"`,
+		`delete a "2"`,
+		`set ` + k + ` "3"`,
+	}
+	if !reflect.DeepEqual(log, want) {
+		t.Errorf("expected %q, found %q", want, log)
+	}
+
+	log = nil
+	s.Expire(ctx, k, time.Hour)
+	s.Touch(ctx, k, 2*time.Hour)
+	s.Persist(ctx, k)
+	if len(log) != 0 {
+		t.Errorf("expected no hook for lifetime changes, found %q", log)
+	}
+}
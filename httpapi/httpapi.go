@@ -0,0 +1,149 @@
+// Package httpapi exposes a mem.Store over HTTP, to inspect and mutate it
+// during development.
+//
+// The routes are:
+//
+//	GET    /keys        the keys, as a JSON array; ?match= filters them by glob pattern
+//	GET    /keys/{key}  the value, with its remaining lifetime in the TTL header
+//	PUT    /keys/{key}  sets the request body as value, expiring after the TTL header if any
+//	DELETE /keys/{key}  deletes the entry
+package httpapi
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gokv/mem"
+)
+
+// TTLHeader carries the lifetime of an entry, in seconds: the remaining one
+// in the responses to GET, the requested one in PUT requests.
+const TTLHeader = "TTL"
+
+// maxBodySize limits the size of the values accepted by PUT.
+const maxBodySize = 32 << 20
+
+type handler struct {
+	s *mem.Store
+}
+
+// Handler returns an http.Handler serving the routes of the package for s.
+func Handler(s *mem.Store) http.Handler {
+	return handler{s: s}
+}
+
+func (h handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.URL.Path == "/keys":
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		h.list(w, r)
+
+	case strings.HasPrefix(r.URL.Path, "/keys/") && len(r.URL.Path) > len("/keys/"):
+		k := strings.TrimPrefix(r.URL.Path, "/keys/")
+		switch r.Method {
+		case http.MethodGet:
+			h.get(w, r, k)
+		case http.MethodPut:
+			h.put(w, r, k)
+		case http.MethodDelete:
+			h.delete(w, r, k)
+		default:
+			w.Header().Set("Allow", "GET, PUT, DELETE")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		}
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (h handler) list(w http.ResponseWriter, r *http.Request) {
+	pattern := r.URL.Query().Get("match")
+	if pattern == "" {
+		pattern = "*"
+	}
+
+	keys, err := h.s.KeysMatching(r.Context(), pattern)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if keys == nil {
+		keys = []string{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(keys)
+}
+
+func (h handler) get(w http.ResponseWriter, r *http.Request, k string) {
+	b, ok, err := h.s.GetBytes(r.Context(), k)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	if ttl, ok, err := h.s.TTL(r.Context(), k); err == nil && ok {
+		w.Header().Set(TTLHeader, strconv.FormatInt(int64(ttl.Round(time.Second)/time.Second), 10))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(b)
+}
+
+func (h handler) put(w http.ResponseWriter, r *http.Request, k string) {
+	var ttl time.Duration
+	if v := r.Header.Get(TTLHeader); v != "" {
+		seconds, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || seconds <= 0 {
+			http.Error(w, "invalid "+TTLHeader+" header", http.StatusBadRequest)
+			return
+		}
+		ttl = time.Duration(seconds) * time.Second
+	}
+
+	b, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	if ttl > 0 {
+		err = h.s.SetWithTimeout(r.Context(), k, json.RawMessage(b), ttl)
+	} else {
+		err = h.s.Set(r.Context(), k, json.RawMessage(b))
+	}
+	if err == mem.ErrInvalidJSON {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h handler) delete(w http.ResponseWriter, r *http.Request, k string) {
+	ok, err := h.s.Delete(r.Context(), k)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,78 @@
+package httpapi_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gokv/mem"
+	"github.com/gokv/mem/httpapi"
+)
+
+func do(t *testing.T, h http.Handler, method, path, body string, header http.Header) *http.Response {
+	t.Helper()
+
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	for k, v := range header {
+		r.Header.Set(k, v[0])
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w.Result()
+}
+
+func TestHandler(t *testing.T) {
+	s := mem.New()
+	defer s.Close()
+
+	h := httpapi.Handler(s)
+
+	if res := do(t, h, http.MethodPut, "/keys/a/b", `{"n":1}`, http.Header{httpapi.TTLHeader: {"60"}}); res.StatusCode != http.StatusNoContent {
+		t.Errorf("PUT: expected status %d, found %d", http.StatusNoContent, res.StatusCode)
+	}
+
+	res := do(t, h, http.MethodGet, "/keys/a/b", "", nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("GET: expected status %d, found %d", http.StatusOK, res.StatusCode)
+	}
+	if b, _ := io.ReadAll(res.Body); string(b) != `{"n":1}` {
+		t.Errorf("GET: expected %q, found %q", `{"n":1}`, b)
+	}
+	if ttl := res.Header.Get(httpapi.TTLHeader); ttl != "60" {
+		t.Errorf("GET: expected TTL %q, found %q", "60", ttl)
+	}
+
+	res = do(t, h, http.MethodGet, "/keys?match=a/*", "", nil)
+	var keys []string
+	json.NewDecoder(res.Body).Decode(&keys)
+	if len(keys) != 1 || keys[0] != "a/b" {
+		t.Errorf("list: expected %q, found %q", []string{"a/b"}, keys)
+	}
+
+	if res := do(t, h, http.MethodDelete, "/keys/a/b", "", nil); res.StatusCode != http.StatusNoContent {
+		t.Errorf("DELETE: expected status %d, found %d", http.StatusNoContent, res.StatusCode)
+	}
+	if ok, _ := s.Exists(context.Background(), "a/b"); ok {
+		t.Error("DELETE: expected the entry to be deleted")
+	}
+
+	for _, tc := range []struct {
+		method, path string
+		header       http.Header
+		status       int
+	}{
+		{http.MethodGet, "/keys/missing", nil, http.StatusNotFound},
+		{http.MethodDelete, "/keys/missing", nil, http.StatusNotFound},
+		{http.MethodPut, "/keys/k", http.Header{httpapi.TTLHeader: {"soon"}}, http.StatusBadRequest},
+		{http.MethodPost, "/keys/k", nil, http.StatusMethodNotAllowed},
+		{http.MethodGet, "/other", nil, http.StatusNotFound},
+	} {
+		if res := do(t, h, tc.method, tc.path, "", tc.header); res.StatusCode != tc.status {
+			t.Errorf("%s %s: expected status %d, found %d", tc.method, tc.path, tc.status, res.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package mem
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/gokv/store"
+)
+
+// ErrUnknownIndex is returned by GetByIndex when no index was declared with
+// the given name.
+var ErrUnknownIndex = errors.New("no index with the given name")
+
+// index maps the values of a JSON field to the keys of the entries holding
+// them.
+type index struct {
+	path   string
+	keys   map[string]map[string]struct{} // by field value
+	values map[string]string              // by key
+}
+
+func newIndex(path string) *index {
+	return &index{
+		path:   path,
+		keys:   make(map[string]map[string]struct{}),
+		values: make(map[string]string),
+	}
+}
+
+func (ix *index) add(k, v string) {
+	keys, ok := ix.keys[v]
+	if !ok {
+		keys = make(map[string]struct{})
+		ix.keys[v] = keys
+	}
+	keys[k] = struct{}{}
+	ix.values[k] = v
+}
+
+func (ix *index) remove(k string) {
+	v, ok := ix.values[k]
+	if !ok {
+		return
+	}
+	delete(ix.values, k)
+	delete(ix.keys[v], k)
+	if len(ix.keys[v]) == 0 {
+		delete(ix.keys, v)
+	}
+}
+
+// reindex updates the indexes for the new entry e of the key k. Values that
+// are not valid JSON are not indexed. The caller must hold the write lock.
+func (s *Store) reindex(k string, e entry) {
+	var doc interface{}
+	if data, err := s.value(e); err == nil {
+		doc, _ = decodeDocument(data)
+	}
+	for _, ix := range s.indexes {
+		ix.remove(k)
+		if v, ok := lookup(doc, ix.path); ok {
+			ix.add(k, v)
+		}
+	}
+}
+
+// unindex removes the key k from the indexes. The caller must hold the write
+// lock.
+func (s *Store) unindex(k string) {
+	for _, ix := range s.indexes {
+		ix.remove(k)
+	}
+}
+
+// GetByIndex returns the values whose field indexed under name, as declared
+// WithIndex, is equal to value.
+// Err is non-nil if the context is Done, if no such index was declared, or
+// if unmarshaling fails.
+func (s *Store) GetByIndex(ctx context.Context, name, value string, c store.Collection) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ix, ok := s.indexes[name]
+	if !ok {
+		return ErrUnknownIndex
+	}
+
+	now := s.clock.Now()
+	for k := range ix.keys[value] {
+		e := s.m[k]
+		if !e.validAt(now) {
+			continue
+		}
+
+		data, err := s.value(e)
+		if err != nil {
+			return err
+		}
+		if err := c.New().UnmarshalJSON(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeDocument decodes data as a generic JSON document, keeping numbers in
+// their literal form.
+func decodeDocument(data []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var doc interface{}
+	err := dec.Decode(&doc)
+	return doc, err
+}
+
+// lookup returns the scalar found in doc at path, as a string. The path is a
+// dot-separated list of object fields and array indexes, optionally starting
+// with "$.", such as "$.addresses.0.city". Strings are returned unquoted,
+// other scalars in their JSON form; objects and arrays are not found.
+func lookup(doc interface{}, path string) (string, bool) {
+	path = strings.TrimPrefix(path, "$.")
+	for _, field := range strings.Split(path, ".") {
+		switch x := doc.(type) {
+		case map[string]interface{}:
+			v, ok := x[field]
+			if !ok {
+				return "", false
+			}
+			doc = v
+		case []interface{}:
+			i, err := strconv.Atoi(field)
+			if err != nil || i < 0 || i >= len(x) {
+				return "", false
+			}
+			doc = x[i]
+		default:
+			return "", false
+		}
+	}
+
+	switch x := doc.(type) {
+	case string:
+		return x, true
+	case json.Number:
+		return x.String(), true
+	case bool:
+		return strconv.FormatBool(x), true
+	case nil:
+		return "null", true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,62 @@
+package mem_test
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/gokv/mem"
+)
+
+func TestGetByIndex(t *testing.T) {
+	ctx := context.Background()
+
+	s := mem.New(
+		mem.WithIndex("email", "email"),
+		mem.WithIndex("city", "$.address.city"),
+	)
+	defer s.Close()
+
+	s.Set(ctx, "1", String(`{"email":"alice@example.com","address":{"city":"Paris"}}`))
+	s.Set(ctx, "2", String(`{"email":"bob@example.com","address":{"city":"Paris"}}`))
+	s.Set(ctx, "3", String(`{"email":"carol@example.com","address":{"city":"Rome"}}`))
+	s.Set(ctx, "4", String(`"not an object"`))
+
+	var c collection
+	if err := s.GetByIndex(ctx, "email", "bob@example.com", &c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(c) != 1 || c[0] != `{"email":"bob@example.com","address":{"city":"Paris"}}` {
+		t.Errorf("expected bob, found %q", c)
+	}
+
+	c = nil
+	s.GetByIndex(ctx, "city", "Paris", &c)
+	if len(c) != 2 {
+		t.Errorf("expected 2 values, found %q", c)
+	}
+
+	t.Run("is maintained on writes", func(t *testing.T) {
+		s.Set(ctx, "2", String(`{"email":"bob@example.com","address":{"city":"Rome"}}`))
+		s.Delete(ctx, "1")
+
+		var c collection
+		s.GetByIndex(ctx, "city", "Paris", &c)
+		if len(c) != 0 {
+			t.Errorf("expected no values, found %q", c)
+		}
+
+		c = nil
+		s.GetByIndex(ctx, "city", "Rome", &c)
+		sort.Slice(c, func(i, j int) bool { return c[i] < c[j] })
+		if len(c) != 2 || c[0] != `{"email":"bob@example.com","address":{"city":"Rome"}}` {
+			t.Errorf("expected bob and carol, found %q", c)
+		}
+	})
+
+	t.Run("unknown index", func(t *testing.T) {
+		if err := s.GetByIndex(ctx, "name", "bob", &c); err != mem.ErrUnknownIndex {
+			t.Errorf("expected error %v, found %v", mem.ErrUnknownIndex, err)
+		}
+	})
+}
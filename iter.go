@@ -0,0 +1,77 @@
+package mem
+
+import (
+	"context"
+	"sort"
+)
+
+// Iterator iterates over the entries of a Snapshot of the Store, in key
+// order. The lock of the Store is only held while taking the Snapshot, so
+// the entries can be processed at any pace without blocking the writers.
+//
+// An Iterator is not safe for concurrent use.
+type Iterator struct {
+	ctx  context.Context
+	sn   *Snapshot
+	keys []string
+
+	key   string
+	value []byte
+	err   error
+}
+
+// Iter returns an Iterator over the valid entries of the Store at the time
+// of the call. Iteration stops when the context is Done.
+func (s *Store) Iter(ctx context.Context) *Iterator {
+	sn := s.Snapshot()
+
+	keys := make([]string, 0, len(sn.m))
+	for k := range sn.m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return &Iterator{ctx: ctx, sn: sn, keys: keys}
+}
+
+// Next advances the Iterator to the next entry, and reports whether there is
+// one. It returns false at the end of the entries, or in case of error.
+func (it *Iterator) Next() bool {
+	if it.err != nil || len(it.keys) == 0 {
+		return false
+	}
+
+	select {
+	case <-it.ctx.Done():
+		it.err = it.ctx.Err()
+		return false
+	default:
+	}
+
+	k := it.keys[0]
+	it.keys = it.keys[1:]
+
+	data, err := it.sn.s.value(it.sn.m[k])
+	if err != nil {
+		it.err = err
+		return false
+	}
+	it.key, it.value = k, data
+	return true
+}
+
+// Key returns the key of the current entry.
+func (it *Iterator) Key() string {
+	return it.key
+}
+
+// Value returns the value of the current entry. It must not be modified.
+func (it *Iterator) Value() []byte {
+	return it.value
+}
+
+// Err returns the error that stopped the iteration, if any: non-nil if the
+// context is Done or if a value cannot be decrypted.
+func (it *Iterator) Err() error {
+	return it.err
+}
@@ -0,0 +1,47 @@
+package mem_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gokv/mem"
+)
+
+func TestIter(t *testing.T) {
+	ctx := context.Background()
+
+	s := mem.New()
+	defer s.Close()
+
+	s.Set(ctx, "b", String(`"2"`))
+	s.Set(ctx, "a", String(`"1"`))
+
+	it := s.Iter(ctx)
+
+	s.Set(ctx, "c", String(`"3"`))
+	s.Delete(ctx, "b")
+
+	var found string
+	for it.Next() {
+		found += it.Key() + "=" + string(it.Value()) + " "
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `a="1" b="2" `; found != want {
+		t.Errorf("expected %q, found %q", want, found)
+	}
+
+	t.Run("stops when the context is Done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		it := s.Iter(ctx)
+		cancel()
+
+		if it.Next() {
+			t.Error("expected the iteration to stop")
+		}
+		if err := it.Err(); err != context.Canceled {
+			t.Errorf("expected error %v, found %v", context.Canceled, err)
+		}
+	})
+}
@@ -0,0 +1,107 @@
+package mem
+
+import (
+	"context"
+	"regexp"
+)
+
+// Keys returns the keys of all the valid entries, in no particular order.
+// Err is non-nil if the context is Done.
+func (s *Store) Keys(ctx context.Context) ([]string, error) {
+	return s.keysMatching(ctx, func(string) bool { return true })
+}
+
+// keysMatching returns the keys of the valid entries for which match returns
+// true. The context is checked between entries.
+func (s *Store) keysMatching(ctx context.Context, match func(k string) bool) ([]string, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := s.clock.Now()
+
+	keys := make([]string, 0)
+	for k, e := range s.m {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		if e.validAt(now) && match(k) {
+			keys = append(keys, k)
+		}
+	}
+	return keys, nil
+}
+
+// KeysMatching returns the keys of all the valid entries matching the given
+// glob pattern, in no particular order. In the pattern, '*' matches any
+// sequence of characters and '?' matches any single character; every other
+// character matches itself.
+// Err is non-nil if the context is Done.
+func (s *Store) KeysMatching(ctx context.Context, pattern string) ([]string, error) {
+	p := []rune(pattern)
+	return s.keysMatching(ctx, func(k string) bool { return matchGlob(p, []rune(k)) })
+}
+
+// KeysRegexp returns the keys of all the valid entries matching the given
+// regular expression, in no particular order.
+// Err is non-nil if the context is Done.
+func (s *Store) KeysRegexp(ctx context.Context, re *regexp.Regexp) ([]string, error) {
+	return s.keysMatching(ctx, re.MatchString)
+}
+
+// matchGlob reports whether name matches the glob pattern p.
+func matchGlob(p, name []rune) bool {
+	var pi, ni int
+	star, starN := -1, 0
+	for ni < len(name) {
+		switch {
+		case pi < len(p) && p[pi] == '*':
+			star, starN = pi, ni
+			pi++
+		case pi < len(p) && (p[pi] == '?' || p[pi] == name[ni]):
+			pi++
+			ni++
+		case star >= 0:
+			// backtrack: let the last star absorb one more character
+			starN++
+			pi, ni = star+1, starN
+		default:
+			return false
+		}
+	}
+	for pi < len(p) && p[pi] == '*' {
+		pi++
+	}
+	return pi == len(p)
+}
+
+// Len returns the number of valid entries.
+// Err is non-nil if the context is Done.
+func (s *Store) Len(ctx context.Context) (int, error) {
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	default:
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := s.clock.Now()
+
+	var n int
+	for _, e := range s.m {
+		if e.validAt(now) {
+			n++
+		}
+	}
+	return n, nil
+}
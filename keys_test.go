@@ -0,0 +1,123 @@
+package mem_test
+
+import (
+	"context"
+	"reflect"
+	"regexp"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/gokv/mem"
+)
+
+func TestKeys(t *testing.T) {
+	s := mem.New()
+	defer s.Close()
+
+	for _, k := range []string{"b", "a", "c"} {
+		if err := s.Set(context.Background(), k, String(k)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.SetWithTimeout(context.Background(), "expired", String("x"), -time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := s.Keys(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sort.Strings(keys)
+	if want := []string{"a", "b", "c"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("expected %q, found %q", want, keys)
+	}
+}
+
+func TestKeysMatching(t *testing.T) {
+	s := mem.New()
+	defer s.Close()
+
+	for _, k := range []string{"session:1", "session:22", "session", "user:1", "sessions:1"} {
+		if err := s.Set(context.Background(), k, String(k)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, tc := range [...]struct {
+		pattern string
+		want    []string
+	}{
+		{"session:*", []string{"session:1", "session:22"}},
+		{"session:?", []string{"session:1"}},
+		{"*:1", []string{"session:1", "sessions:1", "user:1"}},
+		{"s*s*", []string{"session", "session:1", "session:22", "sessions:1"}},
+		{"session", []string{"session"}},
+		{"nothing*", []string{}},
+	} {
+		t.Run(tc.pattern, func(t *testing.T) {
+			keys, err := s.KeysMatching(context.Background(), tc.pattern)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			sort.Strings(keys)
+			if !reflect.DeepEqual(keys, tc.want) {
+				t.Errorf("expected %q, found %q", tc.want, keys)
+			}
+		})
+	}
+}
+
+func TestKeysRegexp(t *testing.T) {
+	s := mem.New()
+	defer s.Close()
+
+	for _, k := range []string{"user:1", "user:22", "user:x"} {
+		if err := s.Set(context.Background(), k, String(k)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	keys, err := s.KeysRegexp(context.Background(), regexp.MustCompile(`^user:\d+$`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sort.Strings(keys)
+	if want := []string{"user:1", "user:22"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("expected %q, found %q", want, keys)
+	}
+
+	t.Run("honors cancellation", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		if _, err := s.KeysRegexp(ctx, regexp.MustCompile(`.*`)); err != context.Canceled {
+			t.Errorf("expected error %v, found %v", context.Canceled, err)
+		}
+	})
+}
+
+func TestLen(t *testing.T) {
+	s := mem.New()
+	defer s.Close()
+
+	for _, k := range []string{"a", "b"} {
+		if err := s.Set(context.Background(), k, String(k)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.SetWithTimeout(context.Background(), "expired", String("x"), -time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := s.Len(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("expected 2 entries, found %d", n)
+	}
+}
@@ -0,0 +1,93 @@
+package mem
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// latencyBuckets is the number of buckets of a Histogram.
+const latencyBuckets = 22
+
+// Histogram is the distribution of the latency of an operation.
+// Counts[i] is the number of operations that took up to 2^i microseconds;
+// the last bucket counts the slower ones.
+type Histogram struct {
+	Counts [latencyBuckets]uint64
+	Total  time.Duration // sum of the latencies
+}
+
+// Count returns the number of operations recorded.
+func (h Histogram) Count() uint64 {
+	var n uint64
+	for _, c := range h.Counts {
+		n += c
+	}
+	return n
+}
+
+// Quantile returns the upper bound of the bucket holding the q-quantile of
+// the latencies, for 0 <= q <= 1. The upper bound of the last bucket is
+// reported as the one of the previous bucket. Returns zero if no operation
+// was recorded.
+func (h Histogram) Quantile(q float64) time.Duration {
+	n := h.Count()
+	if n == 0 {
+		return 0
+	}
+
+	rank := uint64(q * float64(n))
+	if rank >= n {
+		rank = n - 1
+	}
+
+	var seen uint64
+	for i, c := range h.Counts {
+		seen += c
+		if seen > rank {
+			return bucketBound(i)
+		}
+	}
+	return bucketBound(latencyBuckets - 1)
+}
+
+// bucketBound returns the upper bound of the i-th bucket.
+func bucketBound(i int) time.Duration {
+	if i >= latencyBuckets-1 {
+		i = latencyBuckets - 2
+	}
+	return time.Microsecond << uint(i)
+}
+
+// latency records a Histogram atomically.
+type latency struct {
+	counts [latencyBuckets]uint64
+	total  uint64
+}
+
+// since records the time elapsed since start.
+func (l *latency) since(start time.Time) {
+	d := time.Since(start)
+
+	i := 0
+	for i < latencyBuckets-1 && d > bucketBound(i) {
+		i++
+	}
+	atomic.AddUint64(&l.counts[i], 1)
+	atomic.AddUint64(&l.total, uint64(d))
+}
+
+func (l *latency) load() Histogram {
+	var h Histogram
+	for i := range l.counts {
+		h.Counts[i] = atomic.LoadUint64(&l.counts[i])
+	}
+	h.Total = time.Duration(atomic.LoadUint64(&l.total))
+	return h
+}
+
+func (l *latency) reset() {
+	for i := range l.counts {
+		atomic.StoreUint64(&l.counts[i], 0)
+	}
+	atomic.StoreUint64(&l.total, 0)
+}
@@ -0,0 +1,29 @@
+package mem_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gokv/mem"
+)
+
+func TestHistogramQuantile(t *testing.T) {
+	var h mem.Histogram
+	if q := h.Quantile(0.5); q != 0 {
+		t.Errorf("expected 0 without observations, found %v", q)
+	}
+
+	h.Counts[0] = 90  // up to 1µs
+	h.Counts[10] = 10 // up to 1024µs
+
+	for q, want := range map[float64]time.Duration{
+		0:    time.Microsecond,
+		0.5:  time.Microsecond,
+		0.95: 1024 * time.Microsecond,
+		1:    1024 * time.Microsecond,
+	} {
+		if found := h.Quantile(q); found != want {
+			t.Errorf("q%v: expected %v, found %v", q, want, found)
+		}
+	}
+}
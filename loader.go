@@ -0,0 +1,87 @@
+package mem
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Loader returns the value corresponding a key missing from the Store, and
+// its lifetime. A non-positive ttl means the default TTL. A nil value with a
+// nil error means that the key is not found.
+type Loader func(ctx context.Context, k string) (value []byte, ttl time.Duration, err error)
+
+// GetOrLoad is like Get, except that a missing key is loaded with fn, and
+// stored before being returned, regardless of WithLoader. The concurrent
+// misses of the same key share a single call, of the loader of the first of
+// them: the loaders passed by the others are not called, and they get the
+// result of the first loader. Concurrent calls must therefore pass
+// equivalent loaders for the same key.
+func (s *Store) GetOrLoad(ctx context.Context, k string, v json.Unmarshaler, fn Loader) (bool, error) {
+	return s.get(ctx, k, v, fn)
+}
+
+// load calls fn for the missing k, stores its result and unmarshals it into
+// v. If k is assigned concurrently, the assigned value is returned instead.
+func (s *Store) load(ctx context.Context, k string, v json.Unmarshaler, fn Loader) (bool, error) {
+	b, ttl, err := s.flights.do(ctx, k, fn)
+	if err != nil {
+		s.logLoadError(k, err)
+		return false, err
+	}
+	if b == nil {
+		return false, nil
+	}
+
+	e := s.store(k, b, ttl)
+
+	data, err := s.value(e)
+	if err != nil {
+		return true, err
+	}
+	return true, v.UnmarshalJSON(data)
+}
+
+// revalidate reloads the stale k with fn in the background.
+func (s *Store) revalidate(k string, fn Loader) {
+	b, ttl, err := s.flights.do(context.Background(), k, fn)
+	if err != nil {
+		s.logLoadError(k, err)
+		return
+	}
+	if b == nil {
+		return
+	}
+	s.store(k, b, ttl)
+}
+
+// store assigns the loaded value b to k, expiring after ttl, and returns the
+// new entry. If a valid entry was assigned to k in the meantime, it is kept
+// and returned instead. If the Store is frozen, the new entry is returned
+// without being assigned.
+func (s *Store) store(k string, b []byte, ttl time.Duration) entry {
+	e := s.newEntry(k, b)
+	if ttl > 0 {
+		e.validTo = s.clock.Now().Add(s.jittered(ttl)).UnixNano()
+	}
+
+	s.mu.Lock()
+	defer s.unlock()
+
+	if current, ok := s.m[k]; ok && current.validAt(s.clock.Now()) {
+		return current
+	}
+	if s.frozen || s.admit(k, e) != nil {
+		return e
+	}
+	// The value comes from the origin: it is not mirrored back.
+	s.assign(k, e)
+	return e
+}
+
+// logLoadError logs the failure of the loader for k, if a logger is set.
+func (s *Store) logLoadError(k string, err error) {
+	if s.logger != nil {
+		s.logger.Log(EventLoadError, k, "error", err)
+	}
+}
@@ -0,0 +1,116 @@
+package mem_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gokv/mem"
+)
+
+func TestWithLoader(t *testing.T) {
+	ctx := context.Background()
+	errOrigin := errors.New("origin failure")
+
+	var calls int
+	s := mem.New(mem.WithLoader(func(ctx context.Context, k string) ([]byte, time.Duration, error) {
+		calls++
+		switch k {
+		case "found":
+			return []byte(`"loaded"`), time.Minute, nil
+		case "failing":
+			return nil, 0, errOrigin
+		default:
+			return nil, 0, nil
+		}
+	}))
+	defer s.Close()
+
+	for i := 0; i < 2; i++ {
+		var v String
+		if ok, err := s.Get(ctx, "found", &v); !ok || err != nil || v != `"loaded"` {
+			t.Errorf("expected %q, found %q (%v, %v)", `"loaded"`, v, ok, err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("expected the loaded value to be stored, found %d calls", calls)
+	}
+	if ttl, ok, _ := s.TTL(ctx, "found"); !ok || ttl <= 0 || ttl > time.Minute {
+		t.Errorf("expected the loaded TTL, found %v", ttl)
+	}
+
+	var v String
+	if ok, err := s.Get(ctx, "missing", &v); ok || err != nil {
+		t.Errorf("expected (false, nil), found (%v, %v)", ok, err)
+	}
+	if ok, err := s.Get(ctx, "failing", &v); ok || err != errOrigin {
+		t.Errorf("expected error %v, found %v", errOrigin, err)
+	}
+	if ok, _ := s.Exists(ctx, "failing"); ok {
+		t.Error("expected nothing to be stored")
+	}
+}
+
+func TestGetOrLoad(t *testing.T) {
+	ctx := context.Background()
+
+	s := mem.New()
+	defer s.Close()
+
+	var calls int32
+	release := make(chan struct{})
+	load := func(ctx context.Context, k string) ([]byte, time.Duration, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return []byte(`"loaded"`), 0, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var v String
+			if ok, err := s.GetOrLoad(ctx, "key", &v, load); !ok || err != nil || v != `"loaded"` {
+				t.Errorf("expected %q, found %q (%v, %v)", `"loaded"`, v, ok, err)
+			}
+		}()
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("expected the concurrent misses to share 1 call, found %d", n)
+	}
+}
+
+func TestWithStaleWhileRevalidate(t *testing.T) {
+	ctx := context.Background()
+
+	s := mem.New(
+		mem.WithStaleWhileRevalidate(time.Minute),
+		mem.WithLoader(func(ctx context.Context, k string) ([]byte, time.Duration, error) {
+			return []byte(`"fresh"`), time.Minute, nil
+		}),
+	)
+	defer s.Close()
+
+	s.SetWithTimeout(ctx, "key", String(`"stale"`), time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	s.Cleanup(ctx)
+
+	var v String
+	if ok, _ := s.Get(ctx, "key", &v); !ok || v != `"stale"` {
+		t.Errorf("expected %q, found %q", `"stale"`, v)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if ok, _ := s.Get(ctx, "key", &v); !ok || v != `"fresh"` {
+		t.Errorf("expected %q, found %q", `"fresh"`, v)
+	}
+}
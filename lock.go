@@ -0,0 +1,107 @@
+package mem
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strconv"
+	"time"
+)
+
+// lockRetryInterval is the delay between attempts to acquire a held lock.
+const lockRetryInterval = 10 * time.Millisecond
+
+// ErrLockNotHeld is returned by Unlock when the lock is not held with the
+// given token, either because it expired or because it was never acquired.
+var ErrLockNotHeld = errors.New("the lock is not held with the given token")
+
+// Lock acquires an advisory lock on the given key, waiting until it is
+// released, it expires, or the context is Done. The lock is stored as a
+// regular entry holding the returned token, and expires after ttl.
+// Err is non-nil if the context is Done before the lock is acquired, if the
+// Store is frozen, or if the random source set WithRandomSource fails.
+func (s *Store) Lock(ctx context.Context, k string, ttl time.Duration) (string, error) {
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	default:
+	}
+
+	token, err := s.newUUID()
+	if err != nil {
+		return "", err
+	}
+	b := []byte(strconv.Quote(token))
+
+	for {
+		ok, err := s.tryLock(k, b, ttl)
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			return token, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-s.clock.After(lockRetryInterval):
+		}
+	}
+}
+
+// tryLock stores b under the key with the given lifetime, only if the key is
+// not already present.
+func (s *Store) tryLock(k string, b []byte, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.unlock()
+	if s.frozen {
+		return false, ErrReadOnly
+	}
+
+	now := s.clock.Now()
+	if e, ok := s.m[k]; ok && e.validAt(now) {
+		return false, nil
+	}
+
+	e := s.newEntry(k, b)
+	e.validTo = now.Add(ttl).UnixNano()
+	if err := s.admit(k, e); err != nil {
+		return false, err
+	}
+	s.put(k, e)
+	return true, nil
+}
+
+// Unlock releases the lock on the given key, if it is held with the given
+// token.
+// Err is ErrLockNotHeld if the lock is not held with the given token.
+func (s *Store) Unlock(ctx context.Context, k, token string) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	s.mu.Lock()
+	defer s.unlock()
+	if s.frozen {
+		return ErrReadOnly
+	}
+
+	e, ok := s.m[k]
+	if !ok || !e.validAt(s.clock.Now()) {
+		return ErrLockNotHeld
+	}
+
+	data, err := s.value(e)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(data, []byte(strconv.Quote(token))) {
+		return ErrLockNotHeld
+	}
+
+	s.remove(k, Deleted)
+	return nil
+}
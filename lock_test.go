@@ -0,0 +1,83 @@
+package mem_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gokv/mem"
+)
+
+func TestLock(t *testing.T) {
+	t.Run("excludes other holders", func(t *testing.T) {
+		s := mem.New()
+		defer s.Close()
+
+		token, err := s.Lock(context.Background(), "lock", time.Minute)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		if _, err := s.Lock(ctx, "lock", time.Minute); err != context.DeadlineExceeded {
+			t.Errorf("expected error %v, found %v", context.DeadlineExceeded, err)
+		}
+
+		if err := s.Unlock(context.Background(), "lock", token); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if _, err := s.Lock(context.Background(), "lock", time.Minute); err != nil {
+			t.Errorf("unexpected error after unlock: %v", err)
+		}
+	})
+
+	t.Run("expires", func(t *testing.T) {
+		s := mem.New()
+		defer s.Close()
+
+		token, err := s.Lock(context.Background(), "lock", time.Millisecond)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		if _, err := s.Lock(ctx, "lock", time.Minute); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		if err := s.Unlock(context.Background(), "lock", token); err != mem.ErrLockNotHeld {
+			t.Errorf("expected error %v, found %v", mem.ErrLockNotHeld, err)
+		}
+	})
+}
+
+func TestLockRandomSource(t *testing.T) {
+	ctx := context.Background()
+
+	s := mem.New(mem.WithRandomSource(bytes.NewReader(make([]byte, 16))))
+	defer s.Close()
+
+	token, err := s.Lock(ctx, "a", time.Minute)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "00000000-0000-4000-8000-000000000000"; token != want {
+		t.Errorf("expected the token %q from the random source, found %q", want, token)
+	}
+	if _, err := s.Lock(ctx, "b", time.Minute); err == nil {
+		t.Error("expected an error once the source is exhausted")
+	}
+
+	free := mem.New()
+	defer free.Close()
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := free.Lock(canceled, "c", time.Minute); err != context.Canceled {
+		t.Errorf("expected error %v, found %v", context.Canceled, err)
+	}
+}
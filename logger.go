@@ -0,0 +1,28 @@
+package mem
+
+// Logger receives the events of a Store, with the key concerned, if any,
+// and alternating names and values of additional fields.
+type Logger interface {
+	Log(event, key string, fields ...interface{})
+}
+
+// The events passed to a Logger.
+const (
+	// EventSlowCleanup is logged when Cleanup runs out of time before
+	// removing all the expired entries. Fields: duration, error.
+	EventSlowCleanup = "cleanup.slow"
+
+	// EventEvict is logged when an entry is evicted to enforce a capacity.
+	EventEvict = "evict"
+
+	// EventLoadError is logged when the loader fails. Fields: error.
+	EventLoadError = "load.error"
+
+	// EventMarshalError is logged when a value cannot be marshaled. The key
+	// is empty for Add. Fields: error.
+	EventMarshalError = "marshal.error"
+
+	// EventSnapshotError is logged when an automatic snapshot fails.
+	// Fields: path, error.
+	EventSnapshotError = "snapshot.error"
+)
@@ -0,0 +1,70 @@
+package mem_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gokv/mem"
+)
+
+// event is a logged event.
+type event struct {
+	name, key string
+}
+
+// logger records the logged events.
+type logger struct {
+	mu     sync.Mutex
+	events []event
+}
+
+func (l *logger) Log(name, key string, fields ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append(l.events, event{name, key})
+}
+
+// failingValue fails to marshal.
+type failingValue struct{}
+
+func (failingValue) MarshalJSON() ([]byte, error) { return nil, errors.New("marshal failure") }
+
+func TestWithLogger(t *testing.T) {
+	ctx := context.Background()
+
+	l := new(logger)
+	s := mem.New(
+		mem.WithLogger(l),
+		mem.WithMaxEntries(1),
+		mem.WithLoader(func(context.Context, string) ([]byte, time.Duration, error) {
+			return nil, 0, errors.New("origin failure")
+		}),
+	)
+	defer s.Close()
+
+	s.Set(ctx, "a", String(`"v"`))
+	s.Set(ctx, "b", String(`"v"`)) // evicts a
+	s.Set(ctx, "c", failingValue{})
+	var v String
+	s.Get(ctx, "d", &v)
+
+	want := []event{
+		{mem.EventEvict, "a"},
+		{mem.EventMarshalError, "c"},
+		{mem.EventLoadError, "d"},
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.events) != len(want) {
+		t.Fatalf("expected %v, found %v", want, l.events)
+	}
+	for i := range want {
+		if l.events[i] != want[i] {
+			t.Errorf("expected %v, found %v", want[i], l.events[i])
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package mem
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// ErrInvalidJSON is returned when a json.RawMessage value is not valid JSON,
+// if the Store is configured WithJSONValidation.
+var ErrInvalidJSON = errors.New("the value is not valid JSON")
+
+// marshal returns the bytes to store for v under k, which may be empty if
+// not known yet. Values that are already encoded, such as json.RawMessage,
+// are copied instead of marshaled. The result is then checked by the
+// validator, if set.
+func (s *Store) marshal(k string, v json.Marshaler) ([]byte, error) {
+	var b []byte
+	var err error
+	switch v := v.(type) {
+	case json.RawMessage:
+		if s.validateJSON && !json.Valid(v) {
+			err = ErrInvalidJSON
+		} else {
+			b = append([]byte{}, v...)
+		}
+	case raw:
+		b = v
+	default:
+		b, err = v.MarshalJSON()
+	}
+
+	if err != nil && s.logger != nil {
+		s.logger.Log(EventMarshalError, k, "error", err)
+	}
+	if err == nil {
+		if err := s.validate(k, b); err != nil {
+			return nil, err
+		}
+	}
+	return b, err
+}
+
+// validate checks the plaintext value b of k with the validator, if set.
+func (s *Store) validate(k string, b []byte) error {
+	if s.validator == nil {
+		return nil
+	}
+	return s.validator(k, b)
+}
@@ -0,0 +1,107 @@
+package mem_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/gokv/mem"
+)
+
+func TestRawMessage(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("stores a copy", func(t *testing.T) {
+		s := mem.New()
+		defer s.Close()
+
+		msg := json.RawMessage(`{"a":1}`)
+		if err := s.Set(ctx, "key", msg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		msg[1] = 'X'
+
+		var v json.RawMessage
+		if _, err := s.Get(ctx, "key", &v); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if string(v) != `{"a":1}` {
+			t.Errorf("expected %q, found %q", `{"a":1}`, v)
+		}
+	})
+
+	t.Run("validates on demand", func(t *testing.T) {
+		s := mem.New(mem.WithJSONValidation())
+		defer s.Close()
+
+		if err := s.Set(ctx, "key", json.RawMessage(`{"a":`)); err != mem.ErrInvalidJSON {
+			t.Errorf("expected error %v, found %v", mem.ErrInvalidJSON, err)
+		}
+		if err := s.Set(ctx, "key", json.RawMessage(`{"a":1}`)); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestWithValidator(t *testing.T) {
+	ctx := context.Background()
+
+	errTooLarge := errors.New("too large")
+	s := mem.New(mem.WithValidator(func(k string, data []byte) error {
+		if len(data) > 8 {
+			return errTooLarge
+		}
+		return nil
+	}))
+	defer s.Close()
+
+	if err := s.Set(ctx, "small", String(`"small"`)); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := s.Set(ctx, "large", String(`"too large"`)); err != errTooLarge {
+		t.Errorf("expected error %v, found %v", errTooLarge, err)
+	}
+	if _, err := s.Add(ctx, String(`"too large"`)); err != errTooLarge {
+		t.Errorf("expected error %v, found %v", errTooLarge, err)
+	}
+	if ok, _ := s.Exists(ctx, "large"); ok {
+		t.Error("expected the invalid value not to be stored")
+	}
+
+	if _, err := s.Append(ctx, "small", []byte("...")); err != errTooLarge {
+		t.Errorf("Append: expected error %v, found %v", errTooLarge, err)
+	}
+	if _, err := s.Incr(ctx, "counter", 1e9); err != errTooLarge {
+		t.Errorf("Incr: expected error %v, found %v", errTooLarge, err)
+	}
+	doc := `{"large": {"value": "too large"}}`
+	if err := s.ImportJSON(ctx, strings.NewReader(doc)); err != errTooLarge {
+		t.Errorf("ImportJSON: expected error %v, found %v", errTooLarge, err)
+	}
+
+	other := mem.New()
+	defer other.Close()
+	other.Set(ctx, "large", String(`"too large"`))
+	if err := s.Merge(ctx, other, mem.Overwrite); err != errTooLarge {
+		t.Errorf("Merge: expected error %v, found %v", errTooLarge, err)
+	}
+	if err := s.WarmFrom(ctx, other, []string{"large"}); err != errTooLarge {
+		t.Errorf("WarmFrom: expected error %v, found %v", errTooLarge, err)
+	}
+	var buf bytes.Buffer
+	other.SaveTo(ctx, &buf)
+	if err := s.LoadFrom(ctx, &buf); err != errTooLarge {
+		t.Errorf("LoadFrom: expected error %v, found %v", errTooLarge, err)
+	}
+
+	var v String
+	if _, err := s.Get(ctx, "small", &v); err != nil || string(v) != `"small"` {
+		t.Errorf("expected the value to be left unchanged, found %s", v)
+	}
+	if ok, _ := s.Exists(ctx, "large"); ok {
+		t.Error("expected the invalid value not to be stored")
+	}
+}
@@ -0,0 +1,78 @@
+package mem
+
+import "context"
+
+// ConflictPolicy selects the entry kept by Merge when both Stores hold a
+// valid entry for the same key.
+type ConflictPolicy int
+
+const (
+	// KeepNewest keeps the entry written last.
+	KeepNewest ConflictPolicy = iota
+
+	// KeepExisting keeps the entry of the receiving Store.
+	KeepExisting
+
+	// Overwrite keeps the entry of the merged Store.
+	Overwrite
+)
+
+// Merge writes the valid entries of other into the Store, preserving their
+// deadlines, and resolving the keys present in both according to
+// onConflict. The entries of other are copied under its read lock first,
+// then written under a single acquisition of the write lock. other is not
+// modified, and may use a different encryption key.
+// Err is non-nil if the context is Done or if a value of other cannot be
+// decrypted; nothing is written in that case.
+func (s *Store) Merge(ctx context.Context, other *Store, onConflict ConflictPolicy) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	m := other.Snapshot().m
+	for k, e := range m {
+		b, err := other.value(e)
+		if err != nil {
+			return err
+		}
+		if err := s.validate(k, b); err != nil {
+			return err
+		}
+		e.data = b
+		m[k] = e
+	}
+
+	s.mu.Lock()
+	defer s.unlock()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+	if s.frozen {
+		return ErrReadOnly
+	}
+
+	now := s.clock.Now()
+	writes := make(batch, len(m))
+	for k, e := range m {
+		if old, ok := s.m[k]; ok && old.validAt(now) {
+			switch onConflict {
+			case KeepExisting:
+				continue
+			case KeepNewest:
+				if old.updated >= e.updated {
+					continue
+				}
+			}
+		}
+		writes[k] = &entry{data: s.seal(e.data), validTo: e.validTo, idle: e.idle}
+	}
+	if err := s.admitAll(writes); err != nil {
+		return err
+	}
+	writes.apply(s)
+	return nil
+}
@@ -0,0 +1,69 @@
+package mem_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gokv/mem"
+)
+
+func TestMerge(t *testing.T) {
+	ctx := context.Background()
+
+	for _, tc := range [...]struct {
+		name   string
+		policy mem.ConflictPolicy
+		want   String
+	}{
+		{"keep newest", mem.KeepNewest, `"newer"`},
+		{"keep existing", mem.KeepExisting, `"older"`},
+		{"overwrite", mem.Overwrite, `"newer"`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dst, src := mem.New(), mem.New()
+			defer dst.Close()
+			defer src.Close()
+
+			dst.Set(ctx, "conflict", String(`"older"`))
+			dst.Set(ctx, "dst", String(`"dst"`))
+			time.Sleep(time.Millisecond)
+			src.Set(ctx, "conflict", String(`"newer"`))
+			src.SetWithTimeout(ctx, "src", String(`"src"`), time.Minute)
+
+			if err := dst.Merge(ctx, src, tc.policy); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var v String
+			if ok, _ := dst.Get(ctx, "conflict", &v); !ok || v != tc.want {
+				t.Errorf("expected %q, found %q", tc.want, v)
+			}
+			if ok, _ := dst.Get(ctx, "dst", &v); !ok || v != `"dst"` {
+				t.Errorf("expected %q, found %q", `"dst"`, v)
+			}
+			if ttl, ok, _ := dst.TTL(ctx, "src"); !ok || ttl <= 0 || ttl > time.Minute {
+				t.Errorf("expected the deadline to be preserved, found %v", ttl)
+			}
+		})
+	}
+
+	t.Run("keep newest keeps the newer existing entry", func(t *testing.T) {
+		dst, src := mem.New(), mem.New()
+		defer dst.Close()
+		defer src.Close()
+
+		src.Set(ctx, "conflict", String(`"older"`))
+		time.Sleep(time.Millisecond)
+		dst.Set(ctx, "conflict", String(`"newer"`))
+
+		if err := dst.Merge(ctx, src, mem.KeepNewest); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		var v String
+		if ok, _ := dst.Get(ctx, "conflict", &v); !ok || v != `"newer"` {
+			t.Errorf("expected %q, found %q", `"newer"`, v)
+		}
+	})
+}
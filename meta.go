@@ -0,0 +1,57 @@
+package mem
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// Metadata describes the history of an entry.
+type Metadata struct {
+	CreatedAt   time.Time // first write of the key
+	UpdatedAt   time.Time // last write of the value, not of the deadline alone
+	LastAccess  time.Time // last Get, zero if never read
+	AccessCount uint64    // number of Gets since CreatedAt
+}
+
+// access records the reads of an entry. It is updated atomically, as reads
+// happen under the read lock.
+type access struct {
+	last  int64
+	count uint64
+}
+
+func (a *access) touch(t time.Time) {
+	atomic.StoreInt64(&a.last, t.UnixNano())
+	atomic.AddUint64(&a.count, 1)
+}
+
+// Meta returns the Metadata of the entry corresponding the key, without
+// counting as an access.
+// If no match is found, returns (Metadata{}, false, nil).
+// Err is non-nil if the context is Done.
+func (s *Store) Meta(ctx context.Context, k string) (Metadata, bool, error) {
+	select {
+	case <-ctx.Done():
+		return Metadata{}, false, ctx.Err()
+	default:
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	e, ok := s.m[k]
+	if !ok || !e.validAt(s.clock.Now()) {
+		return Metadata{}, false, nil
+	}
+
+	m := Metadata{
+		CreatedAt:   time.Unix(0, e.created),
+		UpdatedAt:   time.Unix(0, e.updated),
+		AccessCount: atomic.LoadUint64(&e.access.count),
+	}
+	if last := atomic.LoadInt64(&e.access.last); last != 0 {
+		m.LastAccess = time.Unix(0, last)
+	}
+	return m, true, nil
+}
@@ -0,0 +1,58 @@
+package mem_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gokv/mem"
+)
+
+func TestMeta(t *testing.T) {
+	ctx := context.Background()
+
+	s := mem.New()
+	defer s.Close()
+
+	before := time.Now()
+	s.Set(ctx, "key", String(`"a"`))
+
+	m, ok, err := s.Meta(ctx, "key")
+	if !ok || err != nil {
+		t.Fatalf("expected (true, nil), found (%v, %v)", ok, err)
+	}
+	if m.CreatedAt.Before(before) || !m.UpdatedAt.Equal(m.CreatedAt) {
+		t.Errorf("unexpected write times: %+v", m)
+	}
+	if !m.LastAccess.IsZero() || m.AccessCount != 0 {
+		t.Errorf("expected no access, found %+v", m)
+	}
+
+	time.Sleep(time.Millisecond)
+	var v String
+	s.Get(ctx, "key", &v)
+	s.Get(ctx, "key", &v)
+	s.Set(ctx, "key", String(`"b"`))
+
+	updated, _, _ := s.Meta(ctx, "key")
+	if !updated.CreatedAt.Equal(m.CreatedAt) {
+		t.Errorf("expected the creation time to be kept, found %v", updated.CreatedAt)
+	}
+	if !updated.UpdatedAt.After(m.UpdatedAt) {
+		t.Errorf("expected the update time to advance, found %v", updated.UpdatedAt)
+	}
+	if updated.AccessCount != 2 || updated.LastAccess.IsZero() {
+		t.Errorf("expected 2 accesses, found %+v", updated)
+	}
+
+	time.Sleep(time.Millisecond)
+	s.Expire(ctx, "key", time.Hour)
+	s.Persist(ctx, "key")
+	if retimed, _, _ := s.Meta(ctx, "key"); !retimed.UpdatedAt.Equal(updated.UpdatedAt) {
+		t.Errorf("expected the update time to be kept by deadline changes, found %v", retimed.UpdatedAt)
+	}
+
+	if _, ok, _ := s.Meta(ctx, "missing"); ok {
+		t.Error("expected no metadata for a missing key")
+	}
+}
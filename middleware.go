@@ -0,0 +1,19 @@
+package mem
+
+import "github.com/gokv/store"
+
+// Middleware wraps a store.Store, like the middlewares of net/http wrap a
+// handler, so that cross-cutting concerns such as logging, metrics or
+// tenancy can be layered onto a Store. A Middleware typically returns a
+// struct embedding next, overriding the methods it intercepts.
+type Middleware func(next store.Store) store.Store
+
+// Chain returns s wrapped by the given middlewares, the first being the
+// outermost: a call goes through mws[0], then mws[1], and so on, before
+// reaching s.
+func Chain(s store.Store, mws ...Middleware) store.Store {
+	for i := len(mws) - 1; i >= 0; i-- {
+		s = mws[i](s)
+	}
+	return s
+}
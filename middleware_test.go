@@ -0,0 +1,52 @@
+package mem_test
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/gokv/mem"
+	"github.com/gokv/store"
+)
+
+// tracing records the Get calls going through it.
+type tracing struct {
+	store.Store
+	name string
+	log  *[]string
+}
+
+func (s tracing) Get(ctx context.Context, k string, v json.Unmarshaler) (bool, error) {
+	*s.log = append(*s.log, s.name+" "+k)
+	return s.Store.Get(ctx, k, v)
+}
+
+func TestChain(t *testing.T) {
+	ctx := context.Background()
+
+	var log []string
+	trace := func(name string) mem.Middleware {
+		return func(next store.Store) store.Store {
+			return tracing{Store: next, name: name, log: &log}
+		}
+	}
+
+	m := mem.New()
+	defer m.Close()
+
+	s := mem.Chain(m, trace("outer"), trace("inner"))
+
+	if err := s.Set(ctx, "key", String(`"value"`)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var v String
+	if ok, _ := s.Get(ctx, "key", &v); !ok || v != `"value"` {
+		t.Errorf("expected %q, found %q", `"value"`, v)
+	}
+
+	if want := []string{"outer key", "inner key"}; !reflect.DeepEqual(log, want) {
+		t.Errorf("expected %q, found %q", want, log)
+	}
+}
@@ -0,0 +1,129 @@
+package mem
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/gokv/store"
+)
+
+// GetMulti retrieves the values corresponding to the given keys under a
+// single read lock, in the order of the keys. Keys that are not found are
+// skipped.
+// Err is non-nil if the context is Done or if unmarshaling fails.
+func (s *Store) GetMulti(ctx context.Context, keys []string, c store.Collection) error {
+	_, err := s.GetMany(ctx, keys, c)
+	return err
+}
+
+// GetMany is like GetMulti, and also returns the number of values found.
+// Err is non-nil if the context is Done or if unmarshaling fails.
+func (s *Store) GetMany(ctx context.Context, keys []string, c store.Collection) (found int, err error) {
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	default:
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := s.clock.Now()
+
+	for _, k := range keys {
+		e, ok := s.m[k]
+		if !ok || !e.validAt(now) {
+			continue
+		}
+		data, err := s.value(e)
+		if err != nil {
+			return found, err
+		}
+		if err := c.New().UnmarshalJSON(data); err != nil {
+			return found, err
+		}
+		found++
+	}
+
+	return found, nil
+}
+
+// SetMulti assigns the given values to the given keys, possibly overwriting.
+// All the values are marshaled before acquiring the lock, so that either
+// all of them or none are written.
+// The returned error is not nil if the context is Done or if marshaling
+// fails.
+func (s *Store) SetMulti(ctx context.Context, values map[string]json.Marshaler) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	m := make(map[string][]byte, len(values))
+	for k, v := range values {
+		b, err := s.marshal(k, v)
+		if err != nil {
+			return err
+		}
+		m[k] = b
+	}
+
+	s.mu.Lock()
+	defer s.unlock()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+	if s.frozen {
+		return ErrReadOnly
+	}
+
+	writes := make(batch, len(m))
+	for k, b := range m {
+		e := s.newEntry(k, b)
+		writes[k] = &e
+	}
+	if err := s.admitAll(writes); err != nil {
+		return err
+	}
+	writes.apply(s)
+	return nil
+}
+
+// DeleteMulti removes the entries corresponding to the given keys, if
+// present, in a single locked pass. It returns the number of entries that
+// were found.
+// Err is non-nil if the context is Done.
+func (s *Store) DeleteMulti(ctx context.Context, keys ...string) (int, error) {
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	default:
+	}
+
+	s.mu.Lock()
+	defer s.unlock()
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	default:
+	}
+	if s.frozen {
+		return 0, ErrReadOnly
+	}
+
+	now := s.clock.Now()
+
+	var deleted int
+	for _, k := range keys {
+		if e, ok := s.m[k]; ok {
+			if e.validAt(now) {
+				deleted++
+			}
+			s.remove(k, Deleted)
+		}
+	}
+	return deleted, nil
+}
@@ -0,0 +1,125 @@
+package mem_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/gokv/mem"
+)
+
+func TestGetMulti(t *testing.T) {
+	s := mem.New()
+	defer s.Close()
+
+	for k, v := range map[string]String{"a": "1", "b": "2", "c": "3"} {
+		if err := s.Set(context.Background(), k, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var c collection
+	if err := s.GetMulti(context.Background(), []string{"c", "missing", "a"}, &c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if want := (collection{"3", "1"}); !reflect.DeepEqual(c, want) {
+		t.Errorf("expected %q, found %q", want, c)
+	}
+}
+
+func TestGetMany(t *testing.T) {
+	s := mem.New()
+	defer s.Close()
+
+	s.Set(context.Background(), "a", String("1"))
+	s.Set(context.Background(), "b", String("2"))
+
+	var c collection
+	found, err := s.GetMany(context.Background(), []string{"b", "missing", "a"}, &c)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if found != 2 {
+		t.Errorf("expected 2 values found, found %d", found)
+	}
+	if want := (collection{"2", "1"}); !reflect.DeepEqual(c, want) {
+		t.Errorf("expected %q, found %q", want, c)
+	}
+}
+
+type failingMarshaler struct{}
+
+func (failingMarshaler) MarshalJSON() ([]byte, error) {
+	return nil, errors.New("marshal failure")
+}
+
+func TestSetMulti(t *testing.T) {
+	t.Run("sets all values", func(t *testing.T) {
+		s := mem.New()
+		defer s.Close()
+
+		err := s.SetMulti(context.Background(), map[string]json.Marshaler{
+			"a": String("1"),
+			"b": String("2"),
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		var c collection
+		if err := s.GetMulti(context.Background(), []string{"a", "b"}, &c); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if want := (collection{"1", "2"}); !reflect.DeepEqual(c, want) {
+			t.Errorf("expected %q, found %q", want, c)
+		}
+	})
+
+	t.Run("sets nothing on marshal failure", func(t *testing.T) {
+		s := mem.New()
+		defer s.Close()
+
+		err := s.SetMulti(context.Background(), map[string]json.Marshaler{
+			"a": String("1"),
+			"b": failingMarshaler{},
+		})
+		if err == nil {
+			t.Fatal("expected an error, found nil")
+		}
+
+		var v String
+		if ok, _ := s.Get(context.Background(), "a", &v); ok {
+			t.Errorf("key %q unexpectedly found: %q", "a", v)
+		}
+	})
+}
+
+func TestDeleteMulti(t *testing.T) {
+	s := mem.New()
+	defer s.Close()
+
+	for _, k := range []string{"a", "b", "c"} {
+		if err := s.Set(context.Background(), k, String(k)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	deleted, err := s.DeleteMulti(context.Background(), "a", "missing", "c")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if deleted != 2 {
+		t.Errorf("expected 2 deleted entries, found %d", deleted)
+	}
+
+	var c collection
+	if err := s.GetMulti(context.Background(), []string{"a", "b", "c"}, &c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := (collection{"b"}); !reflect.DeepEqual(c, want) {
+		t.Errorf("expected %q, found %q", want, c)
+	}
+}
@@ -0,0 +1,160 @@
+package mem
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// defaultEventBuffer is the default capacity of the event channels.
+const defaultEventBuffer = 64
+
+// DropPolicy selects the events dropped when a receiver does not keep up.
+type DropPolicy int
+
+const (
+	// DropNewest drops the new events while the channel is full.
+	DropNewest DropPolicy = iota
+
+	// DropOldest drops the oldest buffered event to make room for the new
+	// one, so that the receiver always gets the latest events.
+	DropOldest
+)
+
+// Op is the kind of mutation notified by an Event.
+type Op int
+
+const (
+	// OpSet is the write of a value.
+	OpSet Op = iota + 1
+
+	// OpDelete is the explicit removal of an entry.
+	OpDelete
+
+	// OpExpire is the removal of an entry past its deadline.
+	OpExpire
+
+	// OpEvict is the removal of an entry to make room for others.
+	OpEvict
+)
+
+func (op Op) String() string {
+	switch op {
+	case OpSet:
+		return "set"
+	case OpDelete:
+		return "delete"
+	case OpExpire:
+		return "expire"
+	case OpEvict:
+		return "evict"
+	default:
+		return "unknown"
+	}
+}
+
+// Event is a mutation of a key.
+type Event struct {
+	Op   Op
+	Key  string
+	Time time.Time
+}
+
+// watcher receives the events of the keys it matches.
+type watcher struct {
+	match func(k string) bool
+	ch    chan Event
+}
+
+// Events returns a channel receiving the events of all the keys, in the
+// order they happen. The channel is closed when the context is Done.
+// Events are never waited for: if the channel buffer is full, events are
+// dropped as configured WithEventBuffer, and counted in Stats.
+// Err is non-nil if the context is Done.
+func (s *Store) Events(ctx context.Context) (<-chan Event, error) {
+	return s.watch(ctx, func(string) bool { return true })
+}
+
+// WatchPrefix is like Events, for the keys starting with prefix.
+func (s *Store) WatchPrefix(ctx context.Context, prefix string) (<-chan Event, error) {
+	return s.watch(ctx, func(k string) bool { return strings.HasPrefix(k, prefix) })
+}
+
+// WatchMatching is like WatchPrefix, for the keys matching the given glob
+// pattern, with the syntax of KeysMatching.
+func (s *Store) WatchMatching(ctx context.Context, pattern string) (<-chan Event, error) {
+	p := []rune(pattern)
+	return s.watch(ctx, func(k string) bool { return matchGlob(p, []rune(k)) })
+}
+
+// watch registers a watcher of the keys matched by match until the context
+// is Done.
+func (s *Store) watch(ctx context.Context, match func(k string) bool) (<-chan Event, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	w := &watcher{match: match, ch: make(chan Event, s.eventBuffer)}
+
+	s.mu.Lock()
+	s.watchers = append(s.watchers, w)
+	s.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for i, other := range s.watchers {
+			if other == w {
+				s.watchers = append(s.watchers[:i], s.watchers[i+1:]...)
+				break
+			}
+		}
+		close(w.ch)
+	}()
+	return w.ch, nil
+}
+
+// notify sends the event to the matching watchers, dropping events for
+// those not keeping up. The caller must hold the write lock.
+func (s *Store) notify(op Op, k string, t time.Time) {
+	e := Event{Op: op, Key: k, Time: t}
+	for _, w := range s.watchers {
+		if !w.match(k) {
+			continue
+		}
+		select {
+		case w.ch <- e:
+			continue
+		default:
+		}
+
+		atomic.AddUint64(&s.stats.droppedEvents, 1)
+		if s.dropPolicy == DropOldest {
+			select {
+			case <-w.ch:
+			default:
+			}
+			select {
+			case w.ch <- e:
+			default:
+			}
+		}
+	}
+}
+
+// removalOp returns the Op notifying a removal for the given reason.
+func removalOp(reason EvictionReason) Op {
+	switch reason {
+	case Expired:
+		return OpExpire
+	case Evicted:
+		return OpEvict
+	default:
+		return OpDelete
+	}
+}
@@ -0,0 +1,106 @@
+package mem_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gokv/mem"
+)
+
+func TestWatchPrefix(t *testing.T) {
+	s := mem.New()
+	defer s.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := s.WatchPrefix(ctx, "flags/")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	s.Set(ctx, "flags/a", String(`true`))
+	s.Set(ctx, "other", String(`true`))
+	s.Delete(ctx, "flags/a")
+	s.SetWithTimeout(ctx, "flags/b", String(`true`), time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	s.Cleanup(ctx)
+
+	for _, want := range []mem.Event{
+		{Op: mem.OpSet, Key: "flags/a"},
+		{Op: mem.OpDelete, Key: "flags/a"},
+		{Op: mem.OpSet, Key: "flags/b"},
+		{Op: mem.OpExpire, Key: "flags/b"},
+	} {
+		select {
+		case e := <-events:
+			if e.Op != want.Op || e.Key != want.Key || e.Time.IsZero() {
+				t.Errorf("expected %v %q, found %v %q at %v", want.Op, want.Key, e.Op, e.Key, e.Time)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("expected %v %q, found nothing", want.Op, want.Key)
+		}
+	}
+
+	cancel()
+	for range events {
+		t.Error("expected no more events")
+	}
+}
+
+func TestWatchMatching(t *testing.T) {
+	s := mem.New()
+	defer s.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, _ := s.WatchMatching(ctx, "user:*:name")
+
+	s.Set(ctx, "user:1:email", String(`"a"`))
+	s.Set(ctx, "user:1:name", String(`"b"`))
+
+	select {
+	case e := <-events:
+		if e.Key != "user:1:name" {
+			t.Errorf("expected %q, found %q", "user:1:name", e.Key)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected an event")
+	}
+}
+
+func TestEvents(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	for _, tc := range []struct {
+		policy mem.DropPolicy
+		want   []string
+	}{
+		{mem.DropNewest, []string{"a", "b"}},
+		{mem.DropOldest, []string{"b", "c"}},
+	} {
+		s := mem.New(mem.WithEventBuffer(2, tc.policy))
+		defer s.Close()
+
+		events, err := s.Events(ctx)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		for _, k := range []string{"a", "b", "c"} {
+			s.Set(ctx, k, String(`"v"`))
+		}
+
+		for _, want := range tc.want {
+			if e := <-events; e.Key != want {
+				t.Errorf("policy %d: expected %q, found %q", tc.policy, want, e.Key)
+			}
+		}
+		if n := s.Stats().DroppedEvents; n != 1 {
+			t.Errorf("policy %d: expected 1 dropped event, found %d", tc.policy, n)
+		}
+	}
+}
@@ -0,0 +1,371 @@
+package mem
+
+import (
+	"io"
+	"time"
+
+	"github.com/gokv/store"
+)
+
+// Option configures a Store. Options are passed to New.
+type Option func(*Store)
+
+// WithDefaultTTL sets the lifetime of the entries written without an
+// explicit deadline, such as with Set or Add. A non-positive d means no
+// default lifetime, which is the default.
+func WithDefaultTTL(d time.Duration) Option {
+	return func(s *Store) {
+		s.defaultTTL = d
+	}
+}
+
+// WithTTLJitter randomly spreads the lifetimes set with SetWithTimeout and
+// the default TTL by up to the given fraction, in both directions, so that
+// entries written together do not all expire at once. For example, with a
+// fraction of 0.1 a one-minute timeout ranges from 54 to 66 seconds.
+// The fraction is clamped to [0, 1].
+func WithTTLJitter(fraction float64) Option {
+	return func(s *Store) {
+		switch {
+		case fraction < 0:
+			fraction = 0
+		case fraction > 1:
+			fraction = 1
+		}
+		s.jitter = fraction
+	}
+}
+
+// WithKeyGenerator sets the function generating the keys of the entries
+// created with Add, in place of random UUIDv4s. On collision, Add retries
+// with new keys as many times as set WithAddRetries, then fails with
+// ErrKeyExists.
+func WithKeyGenerator(newKey func() string) Option {
+	return func(s *Store) {
+		s.newKey = func() (string, error) { return newKey(), nil }
+	}
+}
+
+// WithRandomSource sets the source of randomness of the UUIDv4 keys
+// generated by Add, unless set WithKeyGenerator, and of the tokens of Lock,
+// in place of crypto/rand, so that they are reproducible in tests. r is read
+// under a mutex; if reading fails, such as when r is exhausted, Add and Lock
+// return the error.
+func WithRandomSource(r io.Reader) Option {
+	return func(s *Store) {
+		s.random = r
+	}
+}
+
+// WithAddRetries sets how many times Add generates a new key when the
+// generated one already exists, before failing with ErrKeyExists. The
+// default is 3.
+func WithAddRetries(n int) Option {
+	return func(s *Store) {
+		if n < 0 {
+			n = 0
+		}
+		s.addRetries = n
+	}
+}
+
+// WithCleanupInterval sets the delay between two runs of the background
+// cleanup, which removes the expired entries. The default is one second.
+// A non-positive d disables the background cleanup: expired entries are
+// then only removed by calling Cleanup.
+func WithCleanupInterval(d time.Duration) Option {
+	return func(s *Store) {
+		s.cleanupInterval = d
+	}
+}
+
+// WithCleanupTimeout sets the maximum duration of each run of the background
+// cleanup. The default is one millisecond.
+func WithCleanupTimeout(d time.Duration) Option {
+	return func(s *Store) {
+		s.cleanupTimeout = d
+	}
+}
+
+// WithOnExpire sets a callback run for each expired entry leaving the Store,
+// whether removed by Cleanup or overwritten, with the key and the stored
+// value. The callback is called after the lock is released, and may use the
+// Store.
+func WithOnExpire(fn func(k string, data []byte)) Option {
+	return func(s *Store) {
+		s.onExpire = fn
+	}
+}
+
+// WithOnEvict sets a callback run for each entry leaving the Store, with the
+// key, the stored value and the reason of the removal. The callback is
+// called after the lock is released, and may use the Store.
+func WithOnEvict(fn func(k string, data []byte, reason EvictionReason)) Option {
+	return func(s *Store) {
+		s.onEvict = fn
+	}
+}
+
+// WithOnSet sets a hook run after each successful write, including Add and
+// the atomic operations, with the key and the value written. Changing only
+// the lifetime of an entry, as with Expire or Touch, is not a write. Hooks
+// are called in the order of the writes, after the lock is released, and
+// may use the Store.
+func WithOnSet(fn func(k string, data []byte)) Option {
+	return func(s *Store) {
+		s.onSet = fn
+	}
+}
+
+// WithOnDelete sets a hook run after each explicit deletion of a valid
+// entry, with the key and the value deleted. Expirations and evictions are
+// notified WithOnExpire and WithOnEvict instead. Like WithOnSet, the hook is
+// called after the lock is released, and may use the Store.
+func WithOnDelete(fn func(k string, data []byte)) Option {
+	return func(s *Store) {
+		s.onDelete = fn
+	}
+}
+
+// WithMaxEntries caps the number of entries in the Store. When a write
+// exceeds the cap, entries are evicted according to the eviction policy,
+// which defaults to least recently used. A non-positive n means no cap,
+// which is the default.
+func WithMaxEntries(n int) Option {
+	return func(s *Store) {
+		s.maxEntries = n
+	}
+}
+
+// WithEvictionPolicy sets the policy choosing the entries to evict when the
+// Store exceeds its capacity. The policy must not be shared between Stores.
+func WithEvictionPolicy(p EvictionPolicy) Option {
+	return func(s *Store) {
+		s.policy = p
+	}
+}
+
+// WithMaxBytes caps the total cost of the entries in the Store. When a write
+// exceeds the budget, entries are evicted according to the eviction policy,
+// which defaults to least recently used; an entry costing more than the
+// whole budget is evicted right away. The cost of an entry is the length of
+// its key and value, unless set otherwise with WithCostFunc. A non-positive
+// n means no budget, which is the default.
+func WithMaxBytes(n int64) Option {
+	return func(s *Store) {
+		s.maxBytes = n
+	}
+}
+
+// WithCostFunc sets the function computing the cost of an entry against the
+// budget set with WithMaxBytes.
+func WithCostFunc(fn func(k string, data []byte) int64) Option {
+	return func(s *Store) {
+		s.costFn = fn
+	}
+}
+
+// WithCodec sets the Codec used by Typed to encode and decode values. The
+// default is JSON. Values written with one Codec cannot be read with
+// another.
+func WithCodec(c Codec) Option {
+	return func(s *Store) {
+		s.codec = c
+	}
+}
+
+// WithJSONValidation makes the writes of json.RawMessage values fail with
+// ErrInvalidJSON if they are not valid JSON. By default, json.RawMessage
+// values are stored as is.
+func WithJSONValidation() Option {
+	return func(s *Store) {
+		s.validateJSON = true
+	}
+}
+
+// WithValidator sets a function checking every value before it is written,
+// such as against a schema or a size limit. A write fails with the error
+// returned by fn, and nothing is stored. This covers the values computed
+// by Update, Incr, Append and Allow, and those written by LoadFrom,
+// ImportJSON, Merge and WarmFrom, where a single invalid value fails the whole call.
+// The key passed to fn is empty for Add, whose key is generated after
+// validation. Values returned by a Loader are not validated.
+func WithValidator(fn func(k string, data []byte) error) Option {
+	return func(s *Store) {
+		s.validator = fn
+	}
+}
+
+// WithEncryption encrypts the stored values with AES-GCM under the given
+// key, which must be 16, 24 or 32 bytes long to select AES-128, AES-192 or
+// AES-256. Keys are not encrypted. Values are decrypted on every read;
+// callbacks receive them decrypted, while cost functions and Size see the
+// encrypted bytes.
+// WithEncryption panics if the key has an invalid length.
+func WithEncryption(key []byte) Option {
+	aead, err := newAEAD(key)
+	if err != nil {
+		panic("mem: invalid encryption key: " + err.Error())
+	}
+	return func(s *Store) {
+		s.aead = aead
+	}
+}
+
+// WithAOF makes the Store durable with an append-only log at path. On New,
+// the log is replayed and compacted; then every write, deletion, eviction
+// and expiry is appended to it as the write lock is released, and the log
+// is compacted again whenever it grows past twice the number of entries.
+// The log is synced to disk on compaction and on Close only. Renewals of
+// sliding lifetimes are not logged.
+// Errors writing the log stop the logging, and are returned by Ping and
+// Close. A log file must not be shared between Stores, including the shards
+// of a Sharded store: NewSharded rejects the option.
+func WithAOF(path string) Option {
+	return func(s *Store) {
+		s.aofPath = path
+	}
+}
+
+// WithAutoSnapshot loads the snapshot at path on New, if it exists, then
+// saves a snapshot there every interval and on Close. Snapshots are written
+// with SaveTo to a temporary file, which is atomically renamed to path, so
+// that a crash loses at most one interval of writes. A non-positive every
+// only saves on Close.
+// Errors loading or saving are returned by Ping; Close returns the error of
+// the last snapshot. A snapshot path must not be shared between Stores,
+// including the shards of a Sharded store: NewSharded rejects the option.
+func WithAutoSnapshot(path string, every time.Duration) Option {
+	return func(s *Store) {
+		s.snapshotPath = path
+		s.snapshotEvery = every
+	}
+}
+
+// WithWriteThrough mirrors every write and explicit deletion to backing,
+// typically a durable store, so that it holds the same entries as the
+// Store. Reads are served from memory only.
+// Add, Set, SetWithTimeout, SetWithDeadline, SetTagged and Delete are
+// mirrored before being applied in memory: if backing fails, the error is
+// returned and the write is not applied. Their values are mirrored without
+// the default TTL. The other writes, such as the atomic operations, the
+// batches, the bulk deletions and the changes of lifetime, are mirrored with
+// their deadline once applied, and backing failures are reported to the
+// function set WithOnFlushError. Evictions, expirations and the values
+// returned by a Loader are not mirrored, nor is the state restored
+// WithAOF or WithAutoSnapshot at New.
+// The write lock of the Store is held during the write to backing, so that
+// both apply the writes in the same order: a slow backing store delays the
+// other writes, and backing must not call back into the Store.
+// Values are mirrored in plaintext.
+func WithWriteThrough(backing store.Store) Option {
+	return func(s *Store) {
+		s.backing = backing
+	}
+}
+
+// WithWriteBehind is like WithWriteThrough, except that the mutations it
+// mirrors are queued, never failing, and flushed to backing asynchronously:
+// every flushInterval, as soon as queueSize keys are pending, and on Close.
+// A non-positive flushInterval or queueSize disables the respective trigger.
+// Successive mutations of a key are coalesced, so that only the last one is
+// flushed. Flush failures are reported to the function set
+// WithOnFlushError, and dropped.
+// WithWriteBehind takes precedence over WithWriteThrough.
+func WithWriteBehind(backing store.Store, flushInterval time.Duration, queueSize int) Option {
+	return func(s *Store) {
+		s.behind = newWriteBehind(backing, queueSize)
+		s.flushInterval = flushInterval
+	}
+}
+
+// WithOnFlushError sets a function called with the key and the error of
+// every write-behind mutation failing to flush, and of every write-through
+// mutation failing after being applied. It is called from the flushing
+// goroutine, or with the write lock held for write-through: it should not
+// block, nor use the Store.
+func WithOnFlushError(fn func(k string, err error)) Option {
+	return func(s *Store) {
+		s.onFlushError = fn
+	}
+}
+
+// WithLoader sets a function called on a Get miss. Its result is stored with
+// the returned TTL, spread by the TTL jitter if set, and returned by Get.
+// Errors of the loader are returned by Get, and nothing is stored. The
+// concurrent misses of the same key share a single call of fn.
+func WithLoader(fn Loader) Option {
+	return func(s *Store) {
+		s.loader = fn
+	}
+}
+
+// WithStaleWhileRevalidate keeps the entries for the grace period after
+// their deadline. When such a stale entry is requested with Get or
+// GetOrLoad, it is returned immediately, while the loader refreshes it in
+// the background. Without a loader, stale entries are not returned.
+func WithStaleWhileRevalidate(grace time.Duration) Option {
+	return func(s *Store) {
+		s.staleGrace = grace
+	}
+}
+
+// WithLogger sets a Logger receiving the events of the Store, including the
+// failures of its background goroutines. The Logger must not call the
+// Store, as it may be called while a transaction holds the lock.
+func WithLogger(l Logger) Option {
+	return func(s *Store) {
+		s.logger = l
+	}
+}
+
+// WithEventBuffer sets the capacity of the channels returned by Events,
+// WatchPrefix, WatchMatching and Subscribe, and which events are dropped
+// when they are full. A negative size is treated as zero: events are then only delivered
+// to a receiver already waiting. The default is 64, dropping the newest.
+func WithEventBuffer(size int, policy DropPolicy) Option {
+	return func(s *Store) {
+		if size < 0 {
+			size = 0
+		}
+		s.eventBuffer = size
+		s.dropPolicy = policy
+	}
+}
+
+// WithBucketQuota limits the bucket with the given name, as returned by
+// Bucket. Writes return ErrQuotaExceeded rather than exceeding the quota,
+// so that one bucket cannot fill the Store and cause the entries of the
+// others to be evicted; writes of several keys are then applied in full or
+// not at all. A rejected write does not reach the backing store.
+func WithBucketQuota(name string, q Quota) Option {
+	return func(s *Store) {
+		if s.quotas == nil {
+			s.quotas = make(map[string]*quota)
+		}
+		s.quotas[name] = &quota{Quota: q}
+	}
+}
+
+// WithIndex declares an index under name on the JSON field at path, such as
+// "email" or "$.address.city", maintained on every write so that GetByIndex
+// finds the entries by the value of that field without scanning the Store.
+// Values that are not JSON, or lack the field, are not indexed.
+func WithIndex(name, path string) Option {
+	return func(s *Store) {
+		if s.indexes == nil {
+			s.indexes = make(map[string]*index)
+		}
+		s.indexes[name] = newIndex(path)
+	}
+}
+
+// WithClock sets the Clock deciding when entries expire and pacing the
+// background cleanup, so that tests can advance time instead of sleeping.
+// Latencies and cleanup durations are still measured with the time package.
+// The default is the system clock.
+func WithClock(c Clock) Option {
+	return func(s *Store) {
+		s.clock = c
+	}
+}
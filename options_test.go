@@ -0,0 +1,188 @@
+package mem_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/gokv/mem"
+)
+
+func TestWithDefaultTTL(t *testing.T) {
+	s := mem.New(mem.WithDefaultTTL(time.Minute))
+	defer s.Close()
+
+	if err := s.Set(context.Background(), "set", String("value")); err != nil {
+		t.Fatal(err)
+	}
+	added, err := s.Add(context.Background(), String("value"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetWithTimeout(context.Background(), "explicit", String("value"), time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	for k, max := range map[string]time.Duration{"set": time.Minute, added: time.Minute, "explicit": time.Hour} {
+		ttl, ok, err := s.TTL(context.Background(), k)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !ok {
+			t.Errorf("expected a deadline for %q", k)
+		}
+		if ttl <= max-time.Second || ttl > max {
+			t.Errorf("expected a TTL close to %v for %q, found %v", max, k, ttl)
+		}
+	}
+}
+
+func TestWithTTLJitter(t *testing.T) {
+	s := mem.New(mem.WithTTLJitter(0.5))
+	defer s.Close()
+
+	seen := make(map[time.Duration]bool)
+	for i := 0; i < 10; i++ {
+		k := string(rune('a' + i))
+		if err := s.SetWithTimeout(context.Background(), k, String("value"), time.Hour); err != nil {
+			t.Fatal(err)
+		}
+
+		ttl, _, err := s.TTL(context.Background(), k)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if ttl < 30*time.Minute-time.Second || ttl > 90*time.Minute {
+			t.Errorf("expected a TTL between 30 and 90 minutes, found %v", ttl)
+		}
+		seen[ttl.Round(time.Second)] = true
+	}
+
+	if len(seen) < 2 {
+		t.Error("expected the TTLs to be spread")
+	}
+}
+
+func TestWithKeyGenerator(t *testing.T) {
+	var n int
+	s := mem.New(mem.WithKeyGenerator(func() string {
+		n++
+		return fmt.Sprintf("audit:%03d", n)
+	}))
+	defer s.Close()
+
+	for _, want := range []string{"audit:001", "audit:002"} {
+		k, err := s.Add(context.Background(), String("entry"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if k != want {
+			t.Errorf("expected key %q, found %q", want, k)
+		}
+	}
+
+	t.Run("fails on collision", func(t *testing.T) {
+		s := mem.New(mem.WithKeyGenerator(func() string { return "constant" }))
+		defer s.Close()
+
+		if _, err := s.Add(context.Background(), String("first")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, err := s.Add(context.Background(), String("second")); err != mem.ErrKeyExists {
+			t.Errorf("expected error %v, found %v", mem.ErrKeyExists, err)
+		}
+	})
+}
+
+func TestWithAddRetries(t *testing.T) {
+	keys := []string{"a", "a", "a", "b"}
+	newKey := func() func() string {
+		var i int
+		return func() string {
+			k := keys[i%len(keys)]
+			i++
+			return k
+		}
+	}
+
+	for _, tc := range [...]struct {
+		retries int
+		want    error
+	}{
+		{0, mem.ErrKeyExists},
+		{1, mem.ErrKeyExists},
+		{2, nil},
+	} {
+		t.Run(fmt.Sprintf("%d retries", tc.retries), func(t *testing.T) {
+			s := mem.New(mem.WithKeyGenerator(newKey()), mem.WithAddRetries(tc.retries))
+			defer s.Close()
+
+			if _, err := s.Add(context.Background(), String("first")); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if _, err := s.Add(context.Background(), String("second")); err != tc.want {
+				t.Errorf("expected error %v, found %v", tc.want, err)
+			}
+		})
+	}
+}
+
+// zeroReader reads an endless stream of zero bytes.
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+	return len(p), nil
+}
+
+func TestWithRandomSource(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("generates reproducible keys", func(t *testing.T) {
+		var keys [2]string
+		for i := range keys {
+			s := mem.New(mem.WithRandomSource(rand.New(rand.NewSource(42))))
+			defer s.Close()
+
+			k, err := s.Add(ctx, String("value"))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			keys[i] = k
+		}
+		if keys[0] != keys[1] {
+			t.Errorf("expected the same key twice, found %q and %q", keys[0], keys[1])
+		}
+	})
+
+	t.Run("exercises collisions", func(t *testing.T) {
+		s := mem.New(mem.WithRandomSource(zeroReader{}))
+		defer s.Close()
+
+		if _, err := s.Add(ctx, String("first")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, err := s.Add(ctx, String("second")); err != mem.ErrKeyExists {
+			t.Errorf("expected error %v, found %v", mem.ErrKeyExists, err)
+		}
+	})
+	t.Run("reports read errors", func(t *testing.T) {
+		s := mem.New(mem.WithRandomSource(bytes.NewReader(make([]byte, 16))))
+		defer s.Close()
+
+		if _, err := s.Add(ctx, String("first")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, err := s.Add(ctx, String("second")); err == nil {
+			t.Error("expected an error once the source is exhausted")
+		}
+		if _, err := s.Bucket("b").Add(ctx, String("third")); err == nil {
+			t.Error("expected an error once the source is exhausted")
+		}
+	})
+}
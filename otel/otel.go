@@ -0,0 +1,134 @@
+// Package otel instruments a store.Store, such as a mem.Store, with
+// OpenTelemetry tracing.
+package otel
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/gokv/store"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Attribute keys set on the spans.
+const (
+	KeyAttribute   = "gokv.key"
+	HitAttribute   = "gokv.hit"
+	CountAttribute = "gokv.count"
+)
+
+// Store wraps a store.Store, starting a span for every operation.
+// Get spans record whether the key was found, GetAll spans the number of
+// values returned.
+//
+// Store is safe for concurrent use if the wrapped store is.
+type Store struct {
+	s      store.Store
+	tracer trace.Tracer
+}
+
+// New returns s instrumented with spans started by tracer.
+func New(s store.Store, tracer trace.Tracer) *Store {
+	return &Store{s: s, tracer: tracer}
+}
+
+// start starts a span named after the operation.
+func (s *Store) start(ctx context.Context, op string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	ctx, span := s.tracer.Start(ctx, "mem."+op)
+	span.SetAttributes(attrs...)
+	return ctx, span
+}
+
+// end records err, if any, and ends span.
+func end(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// counter counts the values created by a store.Collection.
+type counter struct {
+	store.Collection
+	n int
+}
+
+func (c *counter) New() json.Unmarshaler {
+	c.n++
+	return c.Collection.New()
+}
+
+// Get calls Get on the wrapped store.
+func (s *Store) Get(ctx context.Context, k string, v json.Unmarshaler) (bool, error) {
+	ctx, span := s.start(ctx, "Get", attribute.String(KeyAttribute, k))
+	ok, err := s.s.Get(ctx, k, v)
+	span.SetAttributes(attribute.Bool(HitAttribute, ok))
+	end(span, err)
+	return ok, err
+}
+
+// GetAll calls GetAll on the wrapped store.
+func (s *Store) GetAll(ctx context.Context, c store.Collection) error {
+	ctx, span := s.start(ctx, "GetAll")
+	cc := &counter{Collection: c}
+	err := s.s.GetAll(ctx, cc)
+	span.SetAttributes(attribute.Int(CountAttribute, cc.n))
+	end(span, err)
+	return err
+}
+
+// Add calls Add on the wrapped store.
+func (s *Store) Add(ctx context.Context, v json.Marshaler) (string, error) {
+	ctx, span := s.start(ctx, "Add")
+	k, err := s.s.Add(ctx, v)
+	span.SetAttributes(attribute.String(KeyAttribute, k))
+	end(span, err)
+	return k, err
+}
+
+// Set calls Set on the wrapped store.
+func (s *Store) Set(ctx context.Context, k string, v json.Marshaler) error {
+	ctx, span := s.start(ctx, "Set", attribute.String(KeyAttribute, k))
+	err := s.s.Set(ctx, k, v)
+	end(span, err)
+	return err
+}
+
+// SetWithTimeout calls SetWithTimeout on the wrapped store.
+func (s *Store) SetWithTimeout(ctx context.Context, k string, v json.Marshaler, timeout time.Duration) error {
+	ctx, span := s.start(ctx, "SetWithTimeout", attribute.String(KeyAttribute, k))
+	err := s.s.SetWithTimeout(ctx, k, v, timeout)
+	end(span, err)
+	return err
+}
+
+// SetWithDeadline calls SetWithDeadline on the wrapped store.
+func (s *Store) SetWithDeadline(ctx context.Context, k string, v json.Marshaler, deadline time.Time) error {
+	ctx, span := s.start(ctx, "SetWithDeadline", attribute.String(KeyAttribute, k))
+	err := s.s.SetWithDeadline(ctx, k, v, deadline)
+	end(span, err)
+	return err
+}
+
+// Delete calls Delete on the wrapped store.
+func (s *Store) Delete(ctx context.Context, k string) (bool, error) {
+	ctx, span := s.start(ctx, "Delete", attribute.String(KeyAttribute, k))
+	ok, err := s.s.Delete(ctx, k)
+	span.SetAttributes(attribute.Bool(HitAttribute, ok))
+	end(span, err)
+	return ok, err
+}
+
+// Ping calls Ping on the wrapped store, without a span.
+func (s *Store) Ping(ctx context.Context) error {
+	return s.s.Ping(ctx)
+}
+
+// Close closes the wrapped store.
+func (s *Store) Close() error {
+	return s.s.Close()
+}
@@ -0,0 +1,79 @@
+package otel_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/gokv/mem"
+	"github.com/gokv/mem/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+type String string
+
+func (s String) MarshalJSON() ([]byte, error) { return []byte(s), nil }
+
+func (s *String) UnmarshalJSON(data []byte) error {
+	*s = String(data)
+	return nil
+}
+
+type collection []*String
+
+func (c *collection) New() json.Unmarshaler {
+	v := new(String)
+	*c = append(*c, v)
+	return v
+}
+
+func attr(span sdktrace.ReadOnlySpan, k string) interface{} {
+	for _, kv := range span.Attributes() {
+		if string(kv.Key) == k {
+			return kv.Value.AsInterface()
+		}
+	}
+	return nil
+}
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+
+	sr := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr)).Tracer("test")
+
+	s := otel.New(mem.New(), tracer)
+	defer s.Close()
+
+	var v String
+	s.Set(ctx, "key", String(`"v"`))
+	s.Get(ctx, "key", &v)
+	s.Get(ctx, "missing", &v)
+	s.GetAll(ctx, new(collection))
+	s.Delete(ctx, "key")
+
+	spans := sr.Ended()
+	if len(spans) != 5 {
+		t.Fatalf("expected 5 spans, found %d", len(spans))
+	}
+
+	for i, want := range []struct {
+		name  string
+		attr  string
+		value interface{}
+	}{
+		{"mem.Set", otel.KeyAttribute, "key"},
+		{"mem.Get", otel.HitAttribute, true},
+		{"mem.Get", otel.HitAttribute, false},
+		{"mem.GetAll", otel.CountAttribute, int64(1)},
+		{"mem.Delete", otel.HitAttribute, true},
+	} {
+		if name := spans[i].Name(); name != want.name {
+			t.Errorf("expected span %q, found %q", want.name, name)
+		}
+		if found := attr(spans[i], want.attr); found != want.value {
+			t.Errorf("%s: expected %s=%v, found %v", want.name, want.attr, want.value, found)
+		}
+	}
+}
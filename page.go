@@ -0,0 +1,110 @@
+package mem
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/gokv/store"
+)
+
+// cursorPrefix marks a cursor returned by GetPage, so that the cursor of a
+// page ending with the empty key is distinct from the empty cursor.
+const cursorPrefix = ">"
+
+// ErrInvalidCursor is returned by GetPage when the cursor was not returned
+// by a previous call to GetPage.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// GetPage returns up to limit values, in key order, starting after the
+// given cursor. The empty cursor starts from the beginning. The returned
+// cursor is to be passed to the next call, and is empty when there are no
+// more values. A non-positive limit returns all remaining values.
+//
+// The read lock is only held for the duration of each call: writes between
+// calls are reflected in later pages if their key has not been passed yet.
+// Err is non-nil if the context is Done, or if unmarshaling fails.
+func (s *Store) GetPage(ctx context.Context, cursor string, limit int, c store.Collection) (string, error) {
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	default:
+	}
+
+	var after string
+	if cursor != "" {
+		if !strings.HasPrefix(cursor, cursorPrefix) {
+			return "", ErrInvalidCursor
+		}
+		after = strings.TrimPrefix(cursor, cursorPrefix)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := s.clock.Now()
+
+	// Keep the smallest limit keys in a max-heap, rather than sorting all
+	// the keys after the cursor.
+	var keys maxKeys
+	var more bool
+	for k, e := range s.m {
+		if (cursor != "" && k <= after) || !e.validAt(now) {
+			continue
+		}
+		switch {
+		case limit <= 0:
+			keys = append(keys, k)
+		case len(keys) < limit:
+			heap.Push(&keys, k)
+		case k < keys[0]:
+			keys[0] = k
+			heap.Fix(&keys, 0)
+			more = true
+		default:
+			more = true
+		}
+	}
+	sort.Strings(keys)
+
+	var next string
+	if more {
+		next = cursorPrefix + keys[len(keys)-1]
+	}
+
+	for _, k := range keys {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		default:
+		}
+
+		data, err := s.value(s.m[k])
+		if err != nil {
+			return "", err
+		}
+		if err := c.New().UnmarshalJSON(data); err != nil {
+			return "", err
+		}
+	}
+
+	return next, nil
+}
+
+// maxKeys is a max-heap of keys.
+type maxKeys []string
+
+func (h maxKeys) Len() int           { return len(h) }
+func (h maxKeys) Less(i, j int) bool { return h[i] > h[j] }
+func (h maxKeys) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *maxKeys) Push(x interface{}) { *h = append(*h, x.(string)) }
+
+func (h *maxKeys) Pop() interface{} {
+	old := *h
+	k := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return k
+}
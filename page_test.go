@@ -0,0 +1,78 @@
+package mem_test
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/gokv/mem"
+)
+
+func TestGetPage(t *testing.T) {
+	s := mem.New()
+	defer s.Close()
+
+	for _, k := range []string{"", "a", "b", "c", "d"} {
+		if err := s.Set(context.Background(), k, String("v"+k)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var (
+		pages  []collection
+		cursor string
+	)
+	for {
+		var c collection
+		next, err := s.GetPage(context.Background(), cursor, 2, &c)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		pages = append(pages, c)
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+
+	want := []collection{{"v", "va"}, {"vb", "vc"}, {"vd"}}
+	if !reflect.DeepEqual(pages, want) {
+		t.Errorf("expected %q, found %q", want, pages)
+	}
+
+	t.Run("walks many keys in order", func(t *testing.T) {
+		s := mem.New()
+		defer s.Close()
+
+		var want collection
+		for i := 0; i < 100; i++ {
+			v := String(fmt.Sprintf("%03d", i))
+			want = append(want, v)
+			s.Set(context.Background(), string(v), v)
+		}
+
+		var found collection
+		var cursor string
+		for {
+			next, err := s.GetPage(context.Background(), cursor, 7, &found)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if next == "" {
+				break
+			}
+			cursor = next
+		}
+		if !reflect.DeepEqual(found, want) {
+			t.Errorf("expected %q, found %q", want, found)
+		}
+	})
+
+	t.Run("rejects foreign cursors", func(t *testing.T) {
+		var c collection
+		if _, err := s.GetPage(context.Background(), "a", 2, &c); err != mem.ErrInvalidCursor {
+			t.Errorf("expected error %v, found %v", mem.ErrInvalidCursor, err)
+		}
+	})
+}
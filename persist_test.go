@@ -0,0 +1,53 @@
+package mem_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gokv/mem"
+)
+
+func TestSaveToLoadFrom(t *testing.T) {
+	ctx := context.Background()
+
+	src := mem.New()
+	defer src.Close()
+
+	src.Set(ctx, "permanent", String(`"a"`))
+	src.SetWithTimeout(ctx, "volatile", String(`"b"`), time.Minute)
+	src.SetWithTimeout(ctx, "short", String(`"c"`), 10*time.Millisecond)
+
+	var buf bytes.Buffer
+	if err := src.SaveTo(ctx, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+
+	dst := mem.New()
+	defer dst.Close()
+
+	if err := dst.LoadFrom(ctx, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var v String
+	if ok, _ := dst.Get(ctx, "permanent", &v); !ok || v != `"a"` {
+		t.Errorf("expected %q, found %q", `"a"`, v)
+	}
+	if ttl, ok, _ := dst.TTL(ctx, "volatile"); !ok || ttl <= 0 || ttl > time.Minute {
+		t.Errorf("expected the deadline to be preserved, found %v", ttl)
+	}
+	if ok, _ := dst.Exists(ctx, "short"); ok {
+		t.Error("expected the entry expired since the save to be skipped")
+	}
+
+	t.Run("rejects other formats", func(t *testing.T) {
+		if err := dst.LoadFrom(ctx, strings.NewReader(`{"not":"a snapshot"}`)); err != mem.ErrSnapshotFormat {
+			t.Errorf("expected error %v, found %v", mem.ErrSnapshotFormat, err)
+		}
+	})
+}
@@ -0,0 +1,92 @@
+package mem
+
+import "container/list"
+
+// EvictionPolicy chooses the entries to evict when the Store exceeds its
+// capacity.
+//
+// The methods of an EvictionPolicy are called by the Store while holding its
+// lock, never concurrently. They must not call the Store.
+type EvictionPolicy interface {
+	// OnGet is called when the entry corresponding to the key is read.
+	OnGet(k string)
+
+	// OnSet is called when an entry is written to the key.
+	OnSet(k string)
+
+	// OnRemove is called when the entry corresponding to the key leaves
+	// the Store, for any reason.
+	OnRemove(k string)
+
+	// Victim returns the key of the next entry to evict, or false if the
+	// policy tracks no keys.
+	Victim() (string, bool)
+}
+
+// NewLRU returns an EvictionPolicy evicting the least recently used entry.
+// Both reads and writes count as uses.
+func NewLRU() EvictionPolicy {
+	return &lru{queue: newQueue()}
+}
+
+// NewFIFO returns an EvictionPolicy evicting the oldest entry, regardless of
+// reads. Overwriting an entry does not change its position.
+func NewFIFO() EvictionPolicy {
+	return &fifo{queue: newQueue()}
+}
+
+// queue is a list of keys, with constant-time lookup.
+type queue struct {
+	ll    *list.List // front is newest
+	byKey map[string]*list.Element
+}
+
+func newQueue() queue {
+	return queue{
+		ll:    list.New(),
+		byKey: make(map[string]*list.Element),
+	}
+}
+
+// push adds the key at the front, or moves it there if moveExisting.
+func (q queue) push(k string, moveExisting bool) {
+	if el, ok := q.byKey[k]; ok {
+		if moveExisting {
+			q.ll.MoveToFront(el)
+		}
+		return
+	}
+	q.byKey[k] = q.ll.PushFront(k)
+}
+
+// touch moves the key to the front, if present.
+func (q queue) touch(k string) {
+	if el, ok := q.byKey[k]; ok {
+		q.ll.MoveToFront(el)
+	}
+}
+
+func (q queue) OnRemove(k string) {
+	if el, ok := q.byKey[k]; ok {
+		q.ll.Remove(el)
+		delete(q.byKey, k)
+	}
+}
+
+func (q queue) Victim() (string, bool) {
+	el := q.ll.Back()
+	if el == nil {
+		return "", false
+	}
+	return el.Value.(string), true
+}
+
+type lru struct{ queue }
+
+func (l *lru) OnGet(k string) { l.touch(k) }
+func (l *lru) OnSet(k string) { l.push(k, true) }
+
+type fifo struct{ queue }
+
+func (f *fifo) OnGet(string)   {}
+func (f *fifo) OnSet(k string) { f.push(k, false) }
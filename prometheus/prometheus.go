@@ -0,0 +1,63 @@
+// Package prometheus exposes the statistics of a mem.Store as Prometheus
+// metrics.
+package prometheus
+
+import (
+	"context"
+
+	"github.com/gokv/mem"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const namespace = "gokv_mem"
+
+var (
+	entriesDesc = prometheus.NewDesc(namespace+"_entries",
+		"Number of entries held, including the expired ones not yet removed.", nil, nil)
+	bytesDesc = prometheus.NewDesc(namespace+"_bytes",
+		"Approximate memory consumed by the keys and values.", nil, nil)
+	hitsDesc = prometheus.NewDesc(namespace+"_hits_total",
+		"Number of Get calls finding a valid entry.", nil, nil)
+	missesDesc = prometheus.NewDesc(namespace+"_misses_total",
+		"Number of Get calls finding no valid entry.", nil, nil)
+	evictionsDesc = prometheus.NewDesc(namespace+"_evictions_total",
+		"Number of entries evicted to enforce a capacity.", nil, nil)
+	expirationsDesc = prometheus.NewDesc(namespace+"_expirations_total",
+		"Number of entries removed after their deadline.", nil, nil)
+	cleanupDesc = prometheus.NewDesc(namespace+"_cleanup_seconds_total",
+		"Total time spent removing the expired entries.", nil, nil)
+)
+
+// collector collects the metrics of a Store on every scrape.
+type collector struct {
+	s *mem.Store
+}
+
+// Collector returns a prometheus.Collector exposing the Stats and the size
+// of s. To register several Stores, wrap the Registerer with distinct
+// labels, as with prometheus.WrapRegistererWith.
+func Collector(s *mem.Store) prometheus.Collector {
+	return collector{s: s}
+}
+
+func (c collector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{
+		entriesDesc, bytesDesc, hitsDesc, missesDesc,
+		evictionsDesc, expirationsDesc, cleanupDesc,
+	} {
+		ch <- d
+	}
+}
+
+func (c collector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.s.Stats()
+	bytes, _, _ := c.s.Size(context.Background())
+
+	ch <- prometheus.MustNewConstMetric(entriesDesc, prometheus.GaugeValue, float64(stats.Entries))
+	ch <- prometheus.MustNewConstMetric(bytesDesc, prometheus.GaugeValue, float64(bytes))
+	ch <- prometheus.MustNewConstMetric(hitsDesc, prometheus.CounterValue, float64(stats.Hits))
+	ch <- prometheus.MustNewConstMetric(missesDesc, prometheus.CounterValue, float64(stats.Misses))
+	ch <- prometheus.MustNewConstMetric(evictionsDesc, prometheus.CounterValue, float64(stats.Evictions))
+	ch <- prometheus.MustNewConstMetric(expirationsDesc, prometheus.CounterValue, float64(stats.Expirations))
+	ch <- prometheus.MustNewConstMetric(cleanupDesc, prometheus.CounterValue, stats.CleanupTime.Seconds())
+}
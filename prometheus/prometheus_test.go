@@ -0,0 +1,28 @@
+package prometheus_test
+
+import (
+	"testing"
+
+	"github.com/gokv/mem"
+	memprometheus "github.com/gokv/mem/prometheus"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestCollector(t *testing.T) {
+	s := mem.New()
+	defer s.Close()
+
+	c := memprometheus.Collector(s)
+
+	descs := make(chan *prometheus.Desc, 16)
+	c.Describe(descs)
+	close(descs)
+
+	metrics := make(chan prometheus.Metric, 16)
+	c.Collect(metrics)
+	close(metrics)
+
+	if len(descs) != len(metrics) {
+		t.Errorf("expected %d metrics, found %d", len(descs), len(metrics))
+	}
+}
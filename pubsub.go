@@ -0,0 +1,99 @@
+package mem
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// pubsub routes the messages published to channels. It is independent from
+// the entries, and has its own lock.
+type pubsub struct {
+	mu   sync.RWMutex
+	subs map[string][]chan []byte
+}
+
+// Publish sends a copy of msg to all the current subscribers of channel,
+// and returns the number of subscribers it was delivered to. Messages are
+// not stored: without subscribers, they are lost.
+// Like events, messages are never waited for, and are dropped for slow
+// subscribers as configured WithEventBuffer. The subscribers share the copy
+// of msg, and must not modify it.
+// Err is non-nil if the context is Done.
+func (s *Store) Publish(ctx context.Context, channel string, msg []byte) (int, error) {
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	default:
+	}
+
+	msg = append([]byte{}, msg...)
+
+	s.pubsub.mu.RLock()
+	defer s.pubsub.mu.RUnlock()
+
+	var n int
+	for _, ch := range s.pubsub.subs[channel] {
+		select {
+		case ch <- msg:
+			n++
+			continue
+		default:
+		}
+
+		atomic.AddUint64(&s.stats.droppedEvents, 1)
+		if s.dropPolicy == DropOldest {
+			select {
+			case <-ch:
+			default:
+			}
+			select {
+			case ch <- msg:
+				n++
+			default:
+			}
+		}
+	}
+	return n, nil
+}
+
+// Subscribe returns a channel receiving the messages published to channel,
+// in order. It is closed when the context is Done.
+// Err is non-nil if the context is Done.
+func (s *Store) Subscribe(ctx context.Context, channel string) (<-chan []byte, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	ch := make(chan []byte, s.eventBuffer)
+
+	s.pubsub.mu.Lock()
+	if s.pubsub.subs == nil {
+		s.pubsub.subs = make(map[string][]chan []byte)
+	}
+	s.pubsub.subs[channel] = append(s.pubsub.subs[channel], ch)
+	s.pubsub.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+
+		s.pubsub.mu.Lock()
+		defer s.pubsub.mu.Unlock()
+		subs := s.pubsub.subs[channel]
+		for i, other := range subs {
+			if other == ch {
+				subs = append(subs[:i], subs[i+1:]...)
+				break
+			}
+		}
+		if len(subs) == 0 {
+			delete(s.pubsub.subs, channel)
+		} else {
+			s.pubsub.subs[channel] = subs
+		}
+		close(ch)
+	}()
+	return ch, nil
+}
@@ -0,0 +1,47 @@
+package mem_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gokv/mem"
+)
+
+func TestPublishSubscribe(t *testing.T) {
+	s := mem.New()
+	defer s.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	a, _ := s.Subscribe(ctx, "news")
+	b, _ := s.Subscribe(ctx, "news")
+	other, _ := s.Subscribe(ctx, "other")
+
+	msg := []byte("hello")
+	if n, err := s.Publish(ctx, "news", msg); n != 2 || err != nil {
+		t.Errorf("expected (2, nil), found (%d, %v)", n, err)
+	}
+	msg[0] = 'j'
+
+	for _, ch := range []<-chan []byte{a, b} {
+		if found := string(<-ch); found != "hello" {
+			t.Errorf("expected %q, found %q", "hello", found)
+		}
+	}
+	select {
+	case m := <-other:
+		t.Errorf("expected no message, found %q", m)
+	default:
+	}
+
+	cancel()
+	for _, ch := range []<-chan []byte{a, b} {
+		for range ch {
+			t.Error("expected the channel to be closed without messages")
+		}
+	}
+	if n, _ := s.Publish(context.Background(), "news", msg); n != 0 {
+		t.Errorf("expected no subscribers, found %d", n)
+	}
+}
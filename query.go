@@ -0,0 +1,26 @@
+package mem
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/gokv/store"
+)
+
+// Query returns the values whose JSON field at path is equal to value. The
+// path is a dot-separated list of object fields and array indexes,
+// optionally starting with "$.", as in "$.addresses.0.city"; strings are
+// compared unquoted, other scalars in their JSON form, such as "42" or
+// "true". Values that are not JSON, or lack the field, do not match.
+// Unlike GetByIndex, Query scans all the entries under the read lock.
+// Err is non-nil if the context is Done or if unmarshaling fails.
+func (s *Store) Query(ctx context.Context, path, value string, c store.Collection) error {
+	return s.getWhere(ctx, func(_ string, data []byte) bool {
+		doc, err := decodeDocument(data)
+		if err != nil {
+			return false
+		}
+		v, ok := lookup(doc, path)
+		return ok && v == value
+	}, func(string) json.Unmarshaler { return c.New() })
+}
@@ -0,0 +1,38 @@
+package mem_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gokv/mem"
+)
+
+func TestQuery(t *testing.T) {
+	ctx := context.Background()
+
+	s := mem.New()
+	defer s.Close()
+
+	s.Set(ctx, "1", String(`{"name":"alice","age":30,"tags":["admin"]}`))
+	s.Set(ctx, "2", String(`{"name":"bob","age":25,"tags":["user"]}`))
+	s.Set(ctx, "3", String(`not json`))
+
+	for _, tc := range [...]struct {
+		path, value string
+		want        int
+	}{
+		{"name", "alice", 1},
+		{"$.age", "25", 1},
+		{"tags.0", "admin", 1},
+		{"tags.1", "admin", 0},
+		{"missing", "alice", 0},
+	} {
+		var c collection
+		if err := s.Query(ctx, tc.path, tc.value, &c); err != nil {
+			t.Fatalf("%s=%s: unexpected error: %v", tc.path, tc.value, err)
+		}
+		if len(c) != tc.want {
+			t.Errorf("%s=%s: expected %d values, found %q", tc.path, tc.value, tc.want, c)
+		}
+	}
+}
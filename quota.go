@@ -0,0 +1,102 @@
+package mem
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// ErrQuotaExceeded is returned when a write would exceed the quota of a
+// Bucket.
+var ErrQuotaExceeded = errors.New("the bucket quota is exceeded")
+
+// Quota limits a Bucket, as configured WithBucketQuota. The zero value of
+// each field means no limit.
+type Quota struct {
+	// MaxEntries is the maximum number of entries in the bucket, including
+	// expired entries not yet removed by Cleanup.
+	MaxEntries int
+
+	// MaxBytes is the maximum total cost of the entries in the bucket, as
+	// computed by the cost function of the Store.
+	MaxBytes int64
+
+	// DefaultTTL overrides the default TTL of the Store for the entries of
+	// the bucket.
+	DefaultTTL time.Duration
+}
+
+// quota is the Quota of a bucket, along with its usage.
+type quota struct {
+	Quota
+	entries int
+	bytes   int64
+}
+
+// quotaOf returns the quota of the bucket of the key k, or nil if none is
+// set.
+func (s *Store) quotaOf(k string) *quota {
+	if len(s.quotas) == 0 {
+		return nil
+	}
+	i := strings.Index(k, bucketSeparator)
+	if i < 0 {
+		return nil
+	}
+	return s.quotas[k[:i]]
+}
+
+// admit returns ErrQuotaExceeded if assigning e to the key k would exceed
+// the quota of its bucket. The caller must hold the write lock.
+func (s *Store) admit(k string, e entry) error {
+	if s.quotaOf(k) == nil {
+		return nil
+	}
+	return s.admitAll(batch{k: &e})
+}
+
+// admitAll returns ErrQuotaExceeded if applying the writes would exceed the
+// quota of a bucket. The caller must hold the write lock.
+func (s *Store) admitAll(writes batch) error {
+	if len(s.quotas) == 0 {
+		return nil
+	}
+
+	delta := make(map[*quota]*quota)
+	for k, e := range writes {
+		q := s.quotaOf(k)
+		if q == nil {
+			continue
+		}
+		d, ok := delta[q]
+		if !ok {
+			d = new(quota)
+			delta[q] = d
+		}
+		if old, ok := s.m[k]; ok {
+			d.entries, d.bytes = d.entries-1, d.bytes-s.costFn(k, old.data)
+		}
+		if e != nil {
+			d.entries, d.bytes = d.entries+1, d.bytes+s.costFn(k, e.data)
+		}
+	}
+
+	for q, d := range delta {
+		if (q.MaxEntries > 0 && d.entries > 0 && q.entries+d.entries > q.MaxEntries) ||
+			(q.MaxBytes > 0 && d.bytes > 0 && q.bytes+d.bytes > q.MaxBytes) {
+			return ErrQuotaExceeded
+		}
+	}
+	return nil
+}
+
+// charge adds n times e to the usage of the bucket of the key k, if it has
+// a quota. The caller must hold the write lock.
+func (s *Store) charge(k string, e entry, n int) {
+	q := s.quotaOf(k)
+	if q == nil {
+		return
+	}
+	q.entries += n
+	q.bytes += int64(n) * s.costFn(k, e.data)
+}
@@ -0,0 +1,101 @@
+package mem_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/gokv/mem"
+)
+
+func TestWithBucketQuota(t *testing.T) {
+	ctx := context.Background()
+
+	s := mem.New(
+		mem.WithBucketQuota("noisy", mem.Quota{MaxEntries: 2, DefaultTTL: time.Minute}),
+		mem.WithBucketQuota("small", mem.Quota{MaxBytes: 20}),
+	)
+	defer s.Close()
+
+	noisy := s.Bucket("noisy")
+	noisy.Set(ctx, "a", String(`"a"`))
+	if _, err := noisy.Add(ctx, String(`"b"`)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := noisy.Set(ctx, "c", String(`"c"`)); err != mem.ErrQuotaExceeded {
+		t.Errorf("expected error %v, found %v", mem.ErrQuotaExceeded, err)
+	}
+	if _, err := noisy.Add(ctx, String(`"c"`)); err != mem.ErrQuotaExceeded {
+		t.Errorf("expected error %v, found %v", mem.ErrQuotaExceeded, err)
+	}
+	if err := noisy.Set(ctx, "a", String(`"overwritten"`)); err != nil {
+		t.Errorf("expected overwriting to be allowed, found %v", err)
+	}
+	if err := s.Bucket("other").Set(ctx, "c", String(`"c"`)); err != nil {
+		t.Errorf("expected the other buckets to be unlimited, found %v", err)
+	}
+
+	if ttl, ok, _ := s.TTL(ctx, "noisy\x00a"); !ok || ttl <= 0 || ttl > time.Minute {
+		t.Errorf("expected the default TTL of the bucket, found %v", ttl)
+	}
+
+	noisy.Delete(ctx, "a")
+	if err := noisy.Set(ctx, "c", String(`"c"`)); err != nil {
+		t.Errorf("expected the deletion to free the quota, found %v", err)
+	}
+
+	small := s.Bucket("small")
+	if err := small.Set(ctx, "k", String(`"1234"`)); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := small.Set(ctx, "k", String(`"1234567890123"`)); err != mem.ErrQuotaExceeded {
+		t.Errorf("expected error %v, found %v", mem.ErrQuotaExceeded, err)
+	}
+	if n, _ := small.Clear(ctx); n != 1 {
+		t.Errorf("expected 1 removal, found %d", n)
+	}
+	if err := small.Set(ctx, "k", String(`"12345"`)); err != nil {
+		t.Errorf("expected clearing to free the quota, found %v", err)
+	}
+}
+
+func TestBucketQuotaWritePaths(t *testing.T) {
+	ctx := context.Background()
+
+	backing := mem.New()
+	defer backing.Close()
+
+	s := mem.New(
+		mem.WithBucketQuota("b", mem.Quota{MaxEntries: 2, MaxBytes: 12}),
+		mem.WithWriteThrough(backing),
+	)
+	defer s.Close()
+
+	if err := s.Set(ctx, "b\x00k", String(`"1234"`)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := s.Append(ctx, "b\x00k", []byte("567890123")); err != mem.ErrQuotaExceeded {
+		t.Errorf("Append: expected error %v, found %v", mem.ErrQuotaExceeded, err)
+	}
+
+	err := s.SetMulti(ctx, map[string]json.Marshaler{
+		"b\x00x": String(`1`),
+		"b\x00y": String(`2`),
+	})
+	if err != mem.ErrQuotaExceeded {
+		t.Errorf("SetMulti: expected error %v, found %v", mem.ErrQuotaExceeded, err)
+	}
+	if ok, _ := s.Get(ctx, "b\x00x", new(String)); ok {
+		t.Error("expected SetMulti to write nothing when over quota")
+	}
+
+	if err := s.Set(ctx, "b\x00k", String(`"1234567890123"`)); err != mem.ErrQuotaExceeded {
+		t.Errorf("Set: expected error %v, found %v", mem.ErrQuotaExceeded, err)
+	}
+	var v String
+	if _, err := backing.Get(ctx, "b\x00k", &v); err != nil || string(v) != `"1234"` {
+		t.Errorf("expected the rejected write not to reach the backing store, found %s", v)
+	}
+}
@@ -0,0 +1,78 @@
+package mem
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"time"
+)
+
+// tokenBucket is the state of a rate limiter, as stored by Allow.
+type tokenBucket struct {
+	Tokens float64 `json:"tokens"`
+	At     int64   `json:"at"` // when Tokens was computed, in nanoseconds
+}
+
+// Allow reports whether an event may happen now under the rate limiter
+// stored at the key: a token bucket holding up to limit tokens, refilled at
+// the rate of limit tokens per window, from which every allowed event takes
+// one. The bucket is stored as a regular entry, expiring once it would be
+// full again, so that idle limiters do not accumulate. A non-positive limit
+// or window allows nothing.
+// Err is non-nil if the context is Done, or if the key holds another value.
+func (s *Store) Allow(ctx context.Context, k string, limit int, window time.Duration) (bool, error) {
+	select {
+	case <-ctx.Done():
+		return false, ctx.Err()
+	default:
+	}
+
+	if limit <= 0 || window <= 0 {
+		return false, nil
+	}
+
+	s.mu.Lock()
+	defer s.unlock()
+	select {
+	case <-ctx.Done():
+		return false, ctx.Err()
+	default:
+	}
+	if s.frozen {
+		return false, ErrReadOnly
+	}
+
+	now := s.clock.Now()
+
+	tokens := float64(limit)
+	if e, ok := s.m[k]; ok && e.validAt(now) {
+		data, err := s.value(e)
+		if err != nil {
+			return false, err
+		}
+		var b tokenBucket
+		if err := json.Unmarshal(data, &b); err != nil {
+			return false, err
+		}
+		refill := float64(limit) * float64(now.UnixNano()-b.At) / float64(window)
+		tokens = math.Min(float64(limit), b.Tokens+refill)
+	}
+
+	if tokens < 1 {
+		return false, nil
+	}
+
+	b, err := json.Marshal(tokenBucket{Tokens: tokens - 1, At: now.UnixNano()})
+	if err != nil {
+		return false, err
+	}
+	if err := s.validate(k, b); err != nil {
+		return false, err
+	}
+	e := entry{data: s.seal(b), validTo: now.Add(window).UnixNano()}
+	if err := s.admit(k, e); err != nil {
+		return false, err
+	}
+	s.put(k, e)
+	return true, nil
+}
@@ -0,0 +1,58 @@
+package mem
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestAllow(t *testing.T) {
+	ctx := context.Background()
+
+	clock := newFakeClock()
+	s := New(WithClock(clock), WithCleanupInterval(0))
+	defer s.Close()
+
+	allow := func() bool {
+		ok, err := s.Allow(ctx, "user:1", 3, 3*time.Second)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return ok
+	}
+
+	for i := 0; i < 3; i++ {
+		if !allow() {
+			t.Fatalf("expected event %d to be allowed", i)
+		}
+	}
+	if allow() {
+		t.Error("expected the burst to be limited")
+	}
+
+	clock.Advance(time.Second)
+	if !allow() {
+		t.Error("expected one token to be refilled")
+	}
+	if allow() {
+		t.Error("expected a single token to be refilled")
+	}
+
+	if ok, _ := s.Allow(ctx, "user:2", 3, time.Second); !ok {
+		t.Error("expected the limiters to be independent")
+	}
+
+	t.Run("expires once full", func(t *testing.T) {
+		clock.Advance(3*time.Second + time.Nanosecond)
+		if ok, _ := s.Exists(ctx, "user:1"); ok {
+			t.Error("expected the idle limiter to expire")
+		}
+	})
+
+	t.Run("rejects other values", func(t *testing.T) {
+		s.Set(ctx, "other", value(`"value"`))
+		if _, err := s.Allow(ctx, "other", 3, time.Second); err == nil {
+			t.Error("expected an error")
+		}
+	})
+}
@@ -0,0 +1,127 @@
+package redisserver
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Limits on the commands read, as enforced by Redis.
+const (
+	maxBulkSize   = 512 << 20 // size of an argument
+	maxMultiBulk  = 1024 * 1024
+	maxInlineSize = 64 << 10 // length of a line
+)
+
+var errProtocol = errors.New("protocol error")
+
+// readCommand reads a command, either as a RESP array of bulk strings or as
+// an inline command.
+func readCommand(r *bufio.Reader) ([][]byte, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 || line[0] != '*' {
+		var args [][]byte
+		for _, f := range strings.Fields(string(line)) {
+			args = append(args, []byte(f))
+		}
+		return args, nil
+	}
+
+	n, err := strconv.Atoi(string(line[1:]))
+	if err != nil || n < 0 || n > maxMultiBulk {
+		return nil, errProtocol
+	}
+	var args [][]byte
+	for i := 0; i < n; i++ {
+		line, err := readLine(r)
+		if err != nil {
+			return nil, err
+		}
+		if len(line) == 0 || line[0] != '$' {
+			return nil, errProtocol
+		}
+		size, err := strconv.Atoi(string(line[1:]))
+		if err != nil || size < 0 || size > maxBulkSize {
+			return nil, errProtocol
+		}
+		// The buffer grows as the data arrives, rather than trusting the
+		// announced size.
+		var buf bytes.Buffer
+		if _, err := io.CopyN(&buf, r, int64(size)+2); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		b := buf.Bytes()
+		if b[size] != '\r' || b[size+1] != '\n' {
+			return nil, errProtocol
+		}
+		args = append(args, b[:size])
+	}
+	return args, nil
+}
+
+// readLine reads a line terminated by CRLF, of up to maxInlineSize bytes,
+// and returns it without the terminator.
+func readLine(r *bufio.Reader) ([]byte, error) {
+	var line []byte
+	for {
+		chunk, err := r.ReadSlice('\n')
+		line = append(line, chunk...)
+		if len(line) > maxInlineSize+2 {
+			return nil, errProtocol
+		}
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		break
+	}
+	if len(line) < 2 || line[len(line)-2] != '\r' {
+		return nil, errProtocol
+	}
+	return line[:len(line)-2], nil
+}
+
+// writer writes RESP replies.
+type writer struct {
+	*bufio.Writer
+}
+
+func (w writer) simple(s string) {
+	w.WriteString("+" + s + "\r\n")
+}
+
+func (w writer) error(s string) {
+	w.WriteString("-" + s + "\r\n")
+}
+
+func (w writer) integer(n int64) {
+	w.WriteString(":" + strconv.FormatInt(n, 10) + "\r\n")
+}
+
+func (w writer) bulk(b []byte) {
+	if b == nil {
+		w.WriteString("$-1\r\n")
+		return
+	}
+	w.WriteString("$" + strconv.Itoa(len(b)) + "\r\n")
+	w.Write(b)
+	w.WriteString("\r\n")
+}
+
+func (w writer) array(items []string) {
+	w.WriteString("*" + strconv.Itoa(len(items)) + "\r\n")
+	for _, item := range items {
+		w.bulk([]byte(item))
+	}
+}
@@ -0,0 +1,36 @@
+package redisserver
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+)
+
+func TestReadCommandLimits(t *testing.T) {
+	for name, input := range map[string]string{
+		"multibulk count": "*9223372036854775807\r\n",
+		"bulk length":     "*1\r\n$9223372036854775807\r\n",
+		"inline length":   strings.Repeat("a", maxInlineSize+1) + "\r\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := readCommand(bufio.NewReader(strings.NewReader(input)))
+			if err != errProtocol {
+				t.Errorf("expected error %v, found %v", errProtocol, err)
+			}
+		})
+	}
+
+	t.Run("truncated bulk", func(t *testing.T) {
+		_, err := readCommand(bufio.NewReader(strings.NewReader("*1\r\n$1000\r\nabc")))
+		if err == nil {
+			t.Error("expected an error")
+		}
+	})
+
+	t.Run("valid command", func(t *testing.T) {
+		args, err := readCommand(bufio.NewReader(strings.NewReader("*2\r\n$3\r\nGET\r\n$3\r\nkey\r\n")))
+		if err != nil || len(args) != 2 || string(args[0]) != "GET" || string(args[1]) != "key" {
+			t.Errorf("expected [GET key], found %q (%v)", args, err)
+		}
+	})
+}
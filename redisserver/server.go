@@ -0,0 +1,313 @@
+// Package redisserver serves a mem.Store with a subset of the Redis
+// protocol, so that Redis clients and tools such as redis-cli can talk to
+// it in tests and local development.
+//
+// The supported commands are PING, GET, SET (with the EX and PX options),
+// DEL, EXISTS, EXPIRE, TTL, KEYS and INCR. KEYS supports the '*' and '?'
+// wildcards, as mem.Store.KeysMatching.
+package redisserver
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gokv/mem"
+)
+
+// ErrServerClosed is returned by Serve after a call to Close.
+var ErrServerClosed = errors.New("redisserver: Server closed")
+
+// value is a Redis string, stored as is.
+type value []byte
+
+func (v value) MarshalJSON() ([]byte, error) { return v, nil }
+
+// arity is the minimum number of arguments of the supported commands.
+var arity = map[string]int{
+	"PING": 0, "GET": 1, "SET": 2, "DEL": 1, "EXISTS": 1,
+	"EXPIRE": 2, "TTL": 1, "KEYS": 1, "INCR": 1,
+}
+
+// Server serves a Store over the Redis protocol.
+//
+// Server is safe for concurrent use.
+type Server struct {
+	s *mem.Store
+
+	mu        sync.Mutex
+	listeners map[net.Listener]struct{}
+	conns     map[net.Conn]struct{}
+	closed    bool
+}
+
+// New returns a Server for s.
+func New(s *mem.Store) *Server {
+	return &Server{
+		s:         s,
+		listeners: make(map[net.Listener]struct{}),
+		conns:     make(map[net.Conn]struct{}),
+	}
+}
+
+// ListenAndServe listens on the TCP address addr, and calls Serve.
+func (srv *Server) ListenAndServe(addr string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return srv.Serve(l)
+}
+
+// Serve accepts connections on l, serving each in its own goroutine, until
+// l fails or the Server is closed. It closes l before returning.
+func (srv *Server) Serve(l net.Listener) error {
+	srv.mu.Lock()
+	if srv.closed {
+		srv.mu.Unlock()
+		l.Close()
+		return ErrServerClosed
+	}
+	srv.listeners[l] = struct{}{}
+	srv.mu.Unlock()
+
+	defer func() {
+		srv.mu.Lock()
+		delete(srv.listeners, l)
+		srv.mu.Unlock()
+		l.Close()
+	}()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			srv.mu.Lock()
+			closed := srv.closed
+			srv.mu.Unlock()
+			if closed {
+				return ErrServerClosed
+			}
+			return err
+		}
+
+		srv.mu.Lock()
+		if srv.closed {
+			srv.mu.Unlock()
+			conn.Close()
+			return ErrServerClosed
+		}
+		srv.conns[conn] = struct{}{}
+		srv.mu.Unlock()
+
+		go srv.serveConn(conn)
+	}
+}
+
+// Close closes the listeners and the connections. It does not close the
+// Store.
+func (srv *Server) Close() error {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	srv.closed = true
+	for l := range srv.listeners {
+		l.Close()
+	}
+	for conn := range srv.conns {
+		conn.Close()
+	}
+	return nil
+}
+
+// serveConn runs the commands read from conn until it fails or is closed.
+func (srv *Server) serveConn(conn net.Conn) {
+	defer func() {
+		srv.mu.Lock()
+		delete(srv.conns, conn)
+		srv.mu.Unlock()
+		conn.Close()
+	}()
+
+	r := bufio.NewReader(conn)
+	w := writer{bufio.NewWriter(conn)}
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			if err == errProtocol {
+				w.error("ERR Protocol error")
+				w.Flush()
+			}
+			return
+		}
+		if len(args) == 0 {
+			continue
+		}
+
+		srv.run(context.Background(), w, args)
+
+		// Flush only when no more pipelined commands are buffered.
+		if r.Buffered() == 0 {
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// run runs a command, and writes its reply to w.
+func (srv *Server) run(ctx context.Context, w writer, args [][]byte) {
+	name := strings.ToUpper(string(args[0]))
+	args = args[1:]
+
+	min, ok := arity[name]
+	if !ok {
+		w.error("ERR unknown command '" + name + "'")
+		return
+	}
+	if len(args) < min {
+		w.error("ERR wrong number of arguments for '" + strings.ToLower(name) + "' command")
+		return
+	}
+
+	switch name {
+	case "PING":
+		if len(args) > 0 {
+			w.bulk(args[0])
+			return
+		}
+		w.simple("PONG")
+
+	case "GET":
+		b, ok, err := srv.s.GetBytes(ctx, string(args[0]))
+		if err != nil {
+			w.error("ERR " + err.Error())
+			return
+		}
+		if !ok {
+			b = nil
+		} else if b == nil {
+			b = []byte{}
+		}
+		w.bulk(b)
+
+	case "SET":
+		srv.set(ctx, w, string(args[0]), args[1], args[2:])
+
+	case "DEL", "EXISTS":
+		var n int64
+		for _, k := range args {
+			var ok bool
+			var err error
+			if name == "DEL" {
+				ok, err = srv.s.Delete(ctx, string(k))
+			} else {
+				ok, err = srv.s.Exists(ctx, string(k))
+			}
+			if err != nil {
+				w.error("ERR " + err.Error())
+				return
+			}
+			if ok {
+				n++
+			}
+		}
+		w.integer(n)
+
+	case "EXPIRE":
+		seconds, err := strconv.ParseInt(string(args[1]), 10, 64)
+		if err != nil {
+			w.error("ERR value is not an integer or out of range")
+			return
+		}
+		if seconds > math.MaxInt64/int64(time.Second) || seconds < math.MinInt64/int64(time.Second) {
+			w.error("ERR invalid expire time in 'expire' command")
+			return
+		}
+		switch err := srv.s.Expire(ctx, string(args[0]), time.Duration(seconds)*time.Second); err {
+		case nil:
+			w.integer(1)
+		case mem.ErrNotFound:
+			w.integer(0)
+		default:
+			w.error("ERR " + err.Error())
+		}
+
+	case "TTL":
+		ttl, ok, err := srv.s.TTL(ctx, string(args[0]))
+		switch {
+		case err == mem.ErrNotFound:
+			w.integer(-2)
+		case err != nil:
+			w.error("ERR " + err.Error())
+		case !ok:
+			w.integer(-1)
+		default:
+			w.integer(int64((ttl + time.Second/2) / time.Second))
+		}
+
+	case "KEYS":
+		keys, err := srv.s.KeysMatching(ctx, string(args[0]))
+		if err != nil {
+			w.error("ERR " + err.Error())
+			return
+		}
+		w.array(keys)
+
+	case "INCR":
+		n, err := srv.s.Incr(ctx, string(args[0]), 1)
+		if err == mem.ErrNotInteger {
+			w.error("ERR value is not an integer or out of range")
+			return
+		}
+		if err == mem.ErrOverflow {
+			w.error("ERR increment or decrement would overflow")
+			return
+		}
+		if err != nil {
+			w.error("ERR " + err.Error())
+			return
+		}
+		w.integer(n)
+	}
+}
+
+// set runs SET k v [EX seconds | PX milliseconds].
+func (srv *Server) set(ctx context.Context, w writer, k string, v []byte, opts [][]byte) {
+	var ttl time.Duration
+	for i := 0; i < len(opts); i++ {
+		opt := strings.ToUpper(string(opts[i]))
+		if (opt != "EX" && opt != "PX") || i+1 == len(opts) {
+			w.error("ERR syntax error")
+			return
+		}
+		i++
+		unit := time.Second
+		if opt == "PX" {
+			unit = time.Millisecond
+		}
+		n, err := strconv.ParseInt(string(opts[i]), 10, 64)
+		if err != nil || n <= 0 || n > math.MaxInt64/int64(unit) {
+			w.error("ERR invalid expire time in 'set' command")
+			return
+		}
+		ttl = time.Duration(n) * unit
+	}
+
+	var err error
+	if ttl > 0 {
+		err = srv.s.SetWithTimeout(ctx, k, value(v), ttl)
+	} else {
+		err = srv.s.Set(ctx, k, value(v))
+	}
+	if err != nil {
+		w.error("ERR " + err.Error())
+		return
+	}
+	w.simple("OK")
+}
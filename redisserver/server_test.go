@@ -0,0 +1,100 @@
+package redisserver_test
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/gokv/mem"
+	"github.com/gokv/mem/redisserver"
+)
+
+// reply reads a whole RESP reply, and returns it with its terminators.
+func reply(t *testing.T, r *bufio.Reader) string {
+	t.Helper()
+
+	line, err := r.ReadString('\n')
+	if err != nil {
+		t.Fatalf("reading reply: %v", err)
+	}
+	switch line[0] {
+	case '$':
+		if line == "$-1\r\n" {
+			return line
+		}
+		body, _ := r.ReadString('\n')
+		return line + body
+	case '*':
+		n := 0
+		for _, c := range line[1 : len(line)-2] {
+			n = n*10 + int(c-'0')
+		}
+		for i := 0; i < n; i++ {
+			line += reply(t, r)
+		}
+	}
+	return line
+}
+
+func TestServer(t *testing.T) {
+	s := mem.New()
+	defer s.Close()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listening: %v", err)
+	}
+	srv := redisserver.New(s)
+	go srv.Serve(l)
+	defer srv.Close()
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatalf("dialing: %v", err)
+	}
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+
+	for _, tc := range []struct {
+		command, reply string
+	}{
+		{"*1\r\n$4\r\nPING\r\n", "+PONG\r\n"},
+		{"*3\r\n$3\r\nSET\r\n$3\r\nkey\r\n$5\r\nhello\r\n", "+OK\r\n"},
+		{"*2\r\n$3\r\nGET\r\n$3\r\nkey\r\n", "$5\r\nhello\r\n"},
+		{"*2\r\n$3\r\nGET\r\n$7\r\nmissing\r\n", "$-1\r\n"},
+		{"*2\r\n$3\r\nTTL\r\n$3\r\nkey\r\n", ":-1\r\n"},
+		{"*3\r\n$6\r\nEXPIRE\r\n$3\r\nkey\r\n$2\r\n60\r\n", ":1\r\n"},
+		{"*2\r\n$3\r\nTTL\r\n$3\r\nkey\r\n", ":60\r\n"},
+		{"*2\r\n$3\r\nTTL\r\n$7\r\nmissing\r\n", ":-2\r\n"},
+		{"INCR counter\r\n", ":1\r\n"},
+		{"INCR counter\r\n", ":2\r\n"},
+		{"INCR key\r\n", "-ERR value is not an integer or out of range\r\n"},
+		{"SET volatile v PX 60000\r\n", "+OK\r\n"},
+		{"KEYS c*\r\n", "*1\r\n$7\r\ncounter\r\n"},
+		{"DEL key counter missing\r\n", ":2\r\n"},
+		{"EXISTS key volatile\r\n", ":1\r\n"},
+		{"SET big v EX 9223372036854775807\r\n", "-ERR invalid expire time in 'set' command\r\n"},
+		{"SET big v PX 9223372036855\r\n", "-ERR invalid expire time in 'set' command\r\n"},
+		{"SET big v EX 0\r\n", "-ERR invalid expire time in 'set' command\r\n"},
+		{"EXPIRE volatile 9223372036854775807\r\n", "-ERR invalid expire time in 'expire' command\r\n"},
+		{"SET k\r\n", "-ERR wrong number of arguments for 'set' command\r\n"},
+		{"FLUSHALL\r\n", "-ERR unknown command 'FLUSHALL'\r\n"},
+	} {
+		if _, err := conn.Write([]byte(tc.command)); err != nil {
+			t.Fatalf("writing: %v", err)
+		}
+		if found := reply(t, r); found != tc.reply {
+			t.Errorf("%q: expected %q, found %q", tc.command, tc.reply, found)
+		}
+	}
+
+	t.Run("pipelining", func(t *testing.T) {
+		conn.Write([]byte(strings.Repeat("PING\r\n", 3)))
+		for i := 0; i < 3; i++ {
+			if found := reply(t, r); found != "+PONG\r\n" {
+				t.Errorf("expected %q, found %q", "+PONG\r\n", found)
+			}
+		}
+	})
+}
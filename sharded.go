@@ -0,0 +1,183 @@
+package mem
+
+import (
+	"context"
+	"encoding/json"
+	"hash/fnv"
+	"runtime"
+	"time"
+
+	"github.com/gokv/store"
+)
+
+// Sharded is an in-memory key-value store split into several Stores, each
+// protected by its own mutex and cleaned up by its own goroutine, to reduce
+// lock contention under heavy concurrent writes.
+// Keys are assigned to shards by hash.
+//
+// Sharded is safe for concurrent use.
+type Sharded struct {
+	shards []*Store
+}
+
+// NewSharded initialises a Sharded store with n shards, applying the given
+// options to each of them: capacities such as WithMaxEntries apply per
+// shard. A non-positive n defaults to GOMAXPROCS.
+// The options holding state that cannot be shared between shards, namely
+// WithEvictionPolicy, WithAOF and WithAutoSnapshot, make NewSharded panic:
+// use NewShardedFunc to configure each shard separately instead.
+func NewSharded(n int, opts ...Option) *Sharded {
+	probe := new(Store)
+	for _, opt := range opts {
+		opt(probe)
+	}
+	switch {
+	case probe.policy != nil:
+		panic("mem: NewSharded: WithEvictionPolicy cannot be shared between shards, use NewShardedFunc")
+	case probe.aofPath != "":
+		panic("mem: NewSharded: WithAOF cannot be shared between shards, use NewShardedFunc")
+	case probe.snapshotPath != "":
+		panic("mem: NewSharded: WithAutoSnapshot cannot be shared between shards, use NewShardedFunc")
+	}
+
+	return NewShardedFunc(n, func(int) *Store { return New(opts...) })
+}
+
+// NewShardedFunc initialises a Sharded store with n shards, the i-th shard
+// being returned by newShard(i). Each shard must have its own eviction
+// policy, append-only log and snapshot path, if any. A non-positive n
+// defaults to GOMAXPROCS.
+func NewShardedFunc(n int, newShard func(i int) *Store) *Sharded {
+	if n <= 0 {
+		n = runtime.GOMAXPROCS(0)
+	}
+
+	s := &Sharded{
+		shards: make([]*Store, n),
+	}
+	for i := range s.shards {
+		s.shards[i] = newShard(i)
+	}
+	return s
+}
+
+// Shard returns the Store holding the given key, giving access to the
+// methods that operate on a single key.
+func (s *Sharded) Shard(k string) *Store {
+	h := fnv.New32a()
+	h.Write([]byte(k))
+	return s.shards[h.Sum32()%uint32(len(s.shards))]
+}
+
+// Get returns the value corresponding the key, and a nil error.
+// If no match is found, returns (false, nil).
+func (s *Sharded) Get(ctx context.Context, k string, v json.Unmarshaler) (bool, error) {
+	return s.Shard(k).Get(ctx, k, v)
+}
+
+// GetAll returns all values, one shard at a time. Error is non-nil if the
+// context is Done.
+func (s *Sharded) GetAll(ctx context.Context, c store.Collection) error {
+	for _, shard := range s.shards {
+		if err := shard.GetAll(ctx, c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Add persists a new object and returns its unique key, generated and
+// marshaled as configured for the first shard.
+// Err is non-nil in case of failure.
+func (s *Sharded) Add(ctx context.Context, v json.Marshaler) (string, error) {
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	default:
+	}
+
+	first := s.shards[0]
+	b, err := first.marshal("", v)
+	if err != nil {
+		return "", err
+	}
+
+	for i := 0; i <= first.addRetries; i++ {
+		k, err := first.newKey()
+		if err != nil {
+			return "", err
+		}
+		ok, err := s.Shard(k).create(ctx, k, b, time.Time{})
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			return k, nil
+		}
+	}
+	return "", ErrKeyExists
+}
+
+// Set assigns the given value to the given key, possibly overwriting.
+// The returned error is not nil if the context is Done.
+func (s *Sharded) Set(ctx context.Context, k string, v json.Marshaler) error {
+	return s.Shard(k).Set(ctx, k, v)
+}
+
+// SetWithTimeout assigns the given value to the given key, possibly
+// overwriting.
+// The assigned key will clear after timeout. The lifespan starts when this
+// function is called.
+func (s *Sharded) SetWithTimeout(ctx context.Context, k string, v json.Marshaler, timeout time.Duration) error {
+	return s.Shard(k).SetWithTimeout(ctx, k, v, timeout)
+}
+
+// SetWithDeadline assigns the given value to the given key, possibly
+// overwriting.
+// The assigned key will clear after deadline.
+func (s *Sharded) SetWithDeadline(ctx context.Context, k string, v json.Marshaler, deadline time.Time) error {
+	return s.Shard(k).SetWithDeadline(ctx, k, v, deadline)
+}
+
+// Delete removes the corresponding entry if present.
+// Returns a non-nil error if the context is Done.
+func (s *Sharded) Delete(ctx context.Context, k string) (bool, error) {
+	return s.Shard(k).Delete(ctx, k)
+}
+
+// Len returns the number of valid entries across all shards.
+// Err is non-nil if the context is Done.
+func (s *Sharded) Len(ctx context.Context) (int, error) {
+	var total int
+	for _, shard := range s.shards {
+		n, err := shard.Len(ctx)
+		if err != nil {
+			return 0, err
+		}
+		total += n
+	}
+	return total, nil
+}
+
+// Ping returns nil if the context is not Done and all the shards are
+// healthy, or the first error returned by the Ping of a shard.
+func (s *Sharded) Ping(ctx context.Context) error {
+	for _, shard := range s.shards {
+		if err := shard.Ping(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close releases the resources associated with all the shards. It returns
+// the first error returned by the Close of a shard.
+func (s *Sharded) Close() error {
+	var err error
+	for _, shard := range s.shards {
+		if e := shard.Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
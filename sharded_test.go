@@ -0,0 +1,119 @@
+package mem_test
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/gokv/mem"
+)
+
+func TestSharded(t *testing.T) {
+	s := mem.NewSharded(4)
+	defer s.Close()
+
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			k := fmt.Sprintf("key%d", i)
+			if err := s.Set(ctx, k, String(k)); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if n, _ := s.Len(ctx); n != 100 {
+		t.Errorf("expected 100 entries, found %d", n)
+	}
+
+	var v String
+	if ok, err := s.Get(ctx, "key42", &v); err != nil || !ok || v != "key42" {
+		t.Errorf("expected %q, found %q (found: %v, err: %v)", "key42", v, ok, err)
+	}
+
+	k, err := s.Add(ctx, String("added"))
+	if err != nil {
+		t.Fatalf("adding: %v", err)
+	}
+	if ok, _ := s.Get(ctx, k, &v); !ok || v != "added" {
+		t.Errorf("expected %q, found %q", "added", v)
+	}
+
+	if ok, _ := s.Delete(ctx, "key42"); !ok {
+		t.Error("expected a deletion")
+	}
+
+	var c collection
+	if err := s.GetAll(ctx, &c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(c) != 100 {
+		t.Errorf("expected 100 values, found %d", len(c))
+	}
+	sort.Slice(c, func(i, j int) bool { return c[i] < c[j] })
+	if c[0] != "added" {
+		t.Errorf("expected the added value, found %q", c[0])
+	}
+}
+
+func TestNewShardedRejectsSharedState(t *testing.T) {
+	for name, opt := range map[string]mem.Option{
+		"WithEvictionPolicy": mem.WithEvictionPolicy(mem.NewFIFO()),
+		"WithAOF":            mem.WithAOF("shared.aof"),
+		"WithAutoSnapshot":   mem.WithAutoSnapshot("shared.snapshot", 0),
+	} {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("expected NewSharded to panic")
+				}
+			}()
+			mem.NewSharded(4, mem.WithMaxEntries(1), opt)
+		})
+	}
+}
+
+func TestNewShardedFunc(t *testing.T) {
+	ctx := context.Background()
+
+	s := mem.NewShardedFunc(4, func(int) *mem.Store {
+		return mem.New(mem.WithMaxEntries(1), mem.WithEvictionPolicy(mem.NewFIFO()))
+	})
+	defer s.Close()
+
+	for i := 0; i < 20; i++ {
+		if err := s.Set(ctx, fmt.Sprintf("key%d", i), String("value")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n, _ := s.Len(ctx); n > 4 {
+		t.Errorf("expected at most one entry per shard, found %d", n)
+	}
+}
+
+func TestShardedPingClose(t *testing.T) {
+	ctx := context.Background()
+	unwritable := filepath.Join(t.TempDir(), "missing", "store.aof")
+
+	s := mem.NewShardedFunc(2, func(i int) *mem.Store {
+		if i == 1 {
+			return mem.New(mem.WithAOF(unwritable))
+		}
+		return mem.New()
+	})
+
+	if err := s.Ping(ctx); err == nil {
+		t.Error("expected Ping to report the failing shard")
+	}
+	if err := s.Close(); err == nil {
+		t.Error("expected Close to report the failing shard")
+	}
+}
@@ -0,0 +1,32 @@
+package mem
+
+import (
+	"context"
+	"unsafe"
+)
+
+// entryOverhead approximates the memory used by an entry besides its key and
+// value bytes: the entry itself and the key header.
+const entryOverhead = int64(unsafe.Sizeof(entry{}) + unsafe.Sizeof(""))
+
+// Size returns the approximate memory consumed by the keys and values, and
+// the number of entries held, including expired entries not yet removed by
+// Cleanup. Bookkeeping such as the map buckets and the eviction policy is
+// not accounted for.
+// Err is non-nil if the context is Done.
+func (s *Store) Size(ctx context.Context) (int64, int, error) {
+	select {
+	case <-ctx.Done():
+		return 0, 0, ctx.Err()
+	default:
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var bytes int64
+	for k, e := range s.m {
+		bytes += int64(len(k)+len(e.data)) + entryOverhead
+	}
+	return bytes, len(s.m), nil
+}
@@ -0,0 +1,38 @@
+package mem_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gokv/mem"
+)
+
+func TestSize(t *testing.T) {
+	s := mem.New()
+	defer s.Close()
+
+	ctx := context.Background()
+
+	empty, n, err := s.Size(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if empty != 0 || n != 0 {
+		t.Errorf("expected an empty store, found %d bytes in %d entries", empty, n)
+	}
+
+	s.Set(ctx, "a", String("1234"))
+	small, _, _ := s.Size(ctx)
+
+	s.Set(ctx, "b", String("12345678"))
+	large, n, err := s.Size(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("expected 2 entries, found %d", n)
+	}
+	if large-2*small != 4 {
+		t.Errorf("expected the second entry to weigh 4 bytes more than the first, found %d and %d", small, large-small)
+	}
+}
@@ -0,0 +1,62 @@
+package mem
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// SetWithIdleTimeout assigns the given value to the given key, possibly
+// overwriting.
+// The assigned key will clear after it has not been read with Get for the
+// duration of idle. The lifespan starts when this function is called, and
+// restarts on every Get.
+func (s *Store) SetWithIdleTimeout(ctx context.Context, k string, v json.Marshaler, idle time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	b, err := s.marshal(k, v)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.unlock()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+	if s.frozen {
+		return ErrReadOnly
+	}
+
+	e := s.newEntry(k, b)
+	e.validTo = s.clock.Now().Add(idle).UnixNano()
+	e.idle = idle
+	if err := s.admit(k, e); err != nil {
+		return err
+	}
+	s.put(k, e)
+	return nil
+}
+
+// slide renews the lifetime of the sliding entry corresponding to the key,
+// if it was not written since version. Renewing is not a write: the version
+// is left unchanged.
+func (s *Store) slide(k string, version uint64, now time.Time) {
+	s.mu.Lock()
+	defer s.unlock()
+
+	e, ok := s.m[k]
+	if !ok || e.version != version || !e.validAt(now) {
+		return
+	}
+
+	e.validTo = now.Add(e.idle).UnixNano()
+	s.m[k] = e
+	s.exp.set(k, e.validTo)
+}
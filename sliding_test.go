@@ -0,0 +1,36 @@
+package mem_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gokv/mem"
+)
+
+func TestSetWithIdleTimeout(t *testing.T) {
+	s := mem.New()
+	defer s.Close()
+
+	idle := 50 * time.Millisecond
+	if err := s.SetWithIdleTimeout(context.Background(), "session", String("value"), idle); err != nil {
+		t.Fatal(err)
+	}
+
+	var v String
+	for i := 0; i < 4; i++ {
+		time.Sleep(idle / 2)
+		ok, err := s.Get(context.Background(), "session", &v)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !ok {
+			t.Fatalf("expected the session to be kept alive by reads (read %d)", i)
+		}
+	}
+
+	time.Sleep(2 * idle)
+	if ok, _ := s.Get(context.Background(), "session", &v); ok {
+		t.Error("expected the session to expire when idle")
+	}
+}
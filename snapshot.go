@@ -0,0 +1,105 @@
+package mem
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/gokv/store"
+)
+
+// Snapshot is an immutable point-in-time view of a Store. It holds the
+// entries that were valid when it was taken, and reading it never acquires
+// the lock of the Store.
+//
+// Snapshot is safe for concurrent use.
+type Snapshot struct {
+	s    *Store // to open sealed values
+	m    map[string]entry
+	time time.Time
+}
+
+// Snapshot copies the valid entries of the Store into a new Snapshot. Only
+// the map is copied: values are shared, as the Store never modifies them in
+// place.
+func (s *Store) Snapshot() *Snapshot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := s.clock.Now()
+
+	m := make(map[string]entry, len(s.m))
+	for k, e := range s.m {
+		if e.validAt(now) {
+			m[k] = e
+		}
+	}
+	return &Snapshot{s: s, m: m, time: now}
+}
+
+// Time returns the time the Snapshot was taken.
+func (sn *Snapshot) Time() time.Time {
+	return sn.time
+}
+
+// Len returns the number of entries in the Snapshot.
+func (sn *Snapshot) Len() int {
+	return len(sn.m)
+}
+
+// Get returns the value corresponding the key, and a nil error.
+// If no match is found, returns (false, nil).
+func (sn *Snapshot) Get(ctx context.Context, k string, v json.Unmarshaler) (bool, error) {
+	select {
+	case <-ctx.Done():
+		return false, ctx.Err()
+	default:
+	}
+
+	e, ok := sn.m[k]
+	if !ok {
+		return false, nil
+	}
+	data, err := sn.s.value(e)
+	if err != nil {
+		return true, err
+	}
+	return true, v.UnmarshalJSON(data)
+}
+
+// GetAll returns all values. Error is non-nil if the context is Done or if
+// unmarshaling fails.
+func (sn *Snapshot) GetAll(ctx context.Context, c store.Collection) error {
+	for _, e := range sn.m {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		data, err := sn.s.value(e)
+		if err != nil {
+			return err
+		}
+		if err := c.New().UnmarshalJSON(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Keys returns all keys, in no particular order.
+// Err is non-nil if the context is Done.
+func (sn *Snapshot) Keys(ctx context.Context) ([]string, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	keys := make([]string, 0, len(sn.m))
+	for k := range sn.m {
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
@@ -0,0 +1,57 @@
+package mem_test
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/gokv/mem"
+)
+
+func TestSnapshot(t *testing.T) {
+	s := mem.New()
+	defer s.Close()
+
+	ctx := context.Background()
+	s.Set(ctx, "a", String("1"))
+	s.Set(ctx, "b", String("2"))
+	s.SetWithTimeout(ctx, "expired", String("x"), -time.Second)
+
+	sn := s.Snapshot()
+
+	s.Set(ctx, "a", String("changed"))
+	s.Delete(ctx, "b")
+	s.Set(ctx, "c", String("3"))
+
+	if sn.Len() != 2 {
+		t.Errorf("expected 2 entries, found %d", sn.Len())
+	}
+
+	var v String
+	if ok, _ := sn.Get(ctx, "a", &v); !ok || v != "1" {
+		t.Errorf("expected %q, found %q", "1", v)
+	}
+	if ok, _ := sn.Get(ctx, "c", &v); ok {
+		t.Errorf("key %q unexpectedly found: %q", "c", v)
+	}
+
+	keys, err := sn.Keys(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sort.Strings(keys)
+	if want := []string{"a", "b"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("expected %q, found %q", want, keys)
+	}
+
+	var c collection
+	if err := sn.GetAll(ctx, &c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sort.Slice(c, func(i, j int) bool { return c[i] < c[j] })
+	if want := (collection{"1", "2"}); !reflect.DeepEqual(c, want) {
+		t.Errorf("expected %q, found %q", want, c)
+	}
+}
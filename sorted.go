@@ -0,0 +1,74 @@
+package mem
+
+import (
+	"context"
+	"sort"
+
+	"github.com/gokv/store"
+)
+
+// Order is the order in which GetAllSorted returns the values.
+type Order int
+
+const (
+	// ByKey sorts the values by key.
+	ByKey Order = iota
+
+	// ByCreatedAt sorts the values by the time their key was first
+	// written, oldest first, then by key.
+	ByCreatedAt
+
+	// ByUpdatedAt sorts the values by the time their key was last written,
+	// oldest first, then by key.
+	ByUpdatedAt
+)
+
+// GetAllSorted returns all the values in the given order, so that the
+// results are deterministic.
+// Err is non-nil if the context is Done or if unmarshaling fails.
+func (s *Store) GetAllSorted(ctx context.Context, c store.Collection, order Order) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := s.clock.Now()
+
+	keys := make([]string, 0, len(s.m))
+	for k, e := range s.m {
+		if e.validAt(now) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := s.m[keys[i]], s.m[keys[j]]
+		switch {
+		case order == ByCreatedAt && a.created != b.created:
+			return a.created < b.created
+		case order == ByUpdatedAt && a.updated != b.updated:
+			return a.updated < b.updated
+		}
+		return keys[i] < keys[j]
+	})
+
+	for _, k := range keys {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		data, err := s.value(s.m[k])
+		if err != nil {
+			return err
+		}
+		if err := c.New().UnmarshalJSON(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package mem_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gokv/mem"
+)
+
+func TestGetAllSorted(t *testing.T) {
+	ctx := context.Background()
+
+	s := mem.New()
+	defer s.Close()
+
+	for _, k := range []string{"c", "a", "b"} {
+		s.Set(ctx, k, String(k))
+		time.Sleep(time.Millisecond)
+	}
+	s.Set(ctx, "c", String("c"))
+
+	for _, tc := range [...]struct {
+		name  string
+		order mem.Order
+		want  string
+	}{
+		{"by key", mem.ByKey, "abc"},
+		{"by created at", mem.ByCreatedAt, "cab"},
+		{"by updated at", mem.ByUpdatedAt, "abc"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var c collection
+			if err := s.GetAllSorted(ctx, &c, tc.order); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var found string
+			for _, v := range c {
+				found += string(v)
+			}
+			if found != tc.want {
+				t.Errorf("expected %q, found %q", tc.want, found)
+			}
+		})
+	}
+}
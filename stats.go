@@ -0,0 +1,98 @@
+package mem
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Stats are the counters of a Store since New, or since the last call to
+// ResetStats.
+type Stats struct {
+	Hits        uint64 // Get calls finding a valid entry
+	Misses      uint64 // Get calls finding none
+	Sets        uint64 // entries written
+	Deletes     uint64 // entries deleted
+	Expirations uint64 // entries removed after their deadline
+	Evictions   uint64 // entries evicted to enforce a capacity
+	Entries     int    // entries currently held, including the expired ones not yet removed
+
+	CleanupTime time.Duration // total time spent in Cleanup
+
+	DroppedEvents uint64 // events and messages not delivered to a slow receiver
+
+	GetLatency    Histogram // of Get
+	SetLatency    Histogram // of Set, SetWithTimeout and SetWithDeadline
+	DeleteLatency Histogram // of Delete
+	CleanupPause  Histogram // of the write lock holds of Cleanup, every cleanupBatchSize removals
+}
+
+// counters are updated atomically, as hits and misses are recorded under the
+// read lock. They are allocated separately from the Store, for alignment.
+type counters struct {
+	hits, misses, sets, deletes, expirations, evictions uint64
+	cleanupNanos, droppedEvents                         uint64
+
+	get, set, del, cleanup latency
+}
+
+// hit records the outcome of a Get.
+func (c *counters) hit(ok bool) {
+	if ok {
+		atomic.AddUint64(&c.hits, 1)
+	} else {
+		atomic.AddUint64(&c.misses, 1)
+	}
+}
+
+// removed records the removal of an entry for the given reason. The caller
+// must report entries found expired as Expired.
+func (c *counters) removed(reason EvictionReason) {
+	switch reason {
+	case Deleted:
+		atomic.AddUint64(&c.deletes, 1)
+	case Expired:
+		atomic.AddUint64(&c.expirations, 1)
+	case Evicted:
+		atomic.AddUint64(&c.evictions, 1)
+	}
+}
+
+// Stats returns the counters of the Store.
+func (s *Store) Stats() Stats {
+	s.mu.RLock()
+	n := len(s.m)
+	s.mu.RUnlock()
+
+	return Stats{
+		Hits:        atomic.LoadUint64(&s.stats.hits),
+		Misses:      atomic.LoadUint64(&s.stats.misses),
+		Sets:        atomic.LoadUint64(&s.stats.sets),
+		Deletes:     atomic.LoadUint64(&s.stats.deletes),
+		Expirations: atomic.LoadUint64(&s.stats.expirations),
+		Evictions:   atomic.LoadUint64(&s.stats.evictions),
+		Entries:     n,
+		CleanupTime: time.Duration(atomic.LoadUint64(&s.stats.cleanupNanos)),
+
+		DroppedEvents: atomic.LoadUint64(&s.stats.droppedEvents),
+
+		GetLatency:    s.stats.get.load(),
+		SetLatency:    s.stats.set.load(),
+		DeleteLatency: s.stats.del.load(),
+		CleanupPause:  s.stats.cleanup.load(),
+	}
+}
+
+// ResetStats sets all the counters of the Store to zero. It does not affect
+// the entries.
+func (s *Store) ResetStats() {
+	for _, c := range []*uint64{
+		&s.stats.hits, &s.stats.misses, &s.stats.sets,
+		&s.stats.deletes, &s.stats.expirations, &s.stats.evictions,
+		&s.stats.cleanupNanos, &s.stats.droppedEvents,
+	} {
+		atomic.StoreUint64(c, 0)
+	}
+	for _, l := range []*latency{&s.stats.get, &s.stats.set, &s.stats.del, &s.stats.cleanup} {
+		l.reset()
+	}
+}
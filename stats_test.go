@@ -0,0 +1,57 @@
+package mem_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gokv/mem"
+)
+
+func TestStats(t *testing.T) {
+	ctx := context.Background()
+
+	s := mem.New(mem.WithMaxEntries(2))
+	defer s.Close()
+
+	var v String
+	s.Set(ctx, "a", String(`"v"`))
+	s.Set(ctx, "b", String(`"v"`))
+	s.Get(ctx, "a", &v)
+	s.Get(ctx, "missing", &v)
+	s.Set(ctx, "c", String(`"v"`)) // evicts b
+	s.Delete(ctx, "a")
+	s.SetWithTimeout(ctx, "d", String(`"v"`), time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	s.Cleanup(ctx)
+
+	want := mem.Stats{
+		Hits:        1,
+		Misses:      1,
+		Sets:        4,
+		Deletes:     1,
+		Expirations: 1,
+		Evictions:   1,
+		Entries:     1,
+	}
+	found := s.Stats()
+	if found.CleanupTime <= 0 {
+		t.Errorf("expected the cleanup time to be recorded, found %v", found.CleanupTime)
+	}
+	if n := found.GetLatency.Count(); n != 2 {
+		t.Errorf("expected 2 Get latencies, found %d", n)
+	}
+	if n := found.SetLatency.Count(); n != 4 {
+		t.Errorf("expected 4 Set latencies, found %d", n)
+	}
+	found.CleanupTime = 0
+	found.GetLatency, found.SetLatency, found.DeleteLatency, found.CleanupPause = mem.Histogram{}, mem.Histogram{}, mem.Histogram{}, mem.Histogram{}
+	if found != want {
+		t.Errorf("expected %+v, found %+v", want, found)
+	}
+
+	s.ResetStats()
+	if found := s.Stats(); found != (mem.Stats{Entries: 1}) {
+		t.Errorf("expected the counters to be reset, found %+v", found)
+	}
+}
@@ -2,9 +2,13 @@ package mem
 
 import (
 	"context"
+	"crypto/cipher"
 	"encoding/json"
 	"errors"
+	"io"
+	"math/rand"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gokv/store"
@@ -14,14 +18,37 @@ import (
 const (
 	cleanupInterval = time.Second
 	cleanupTimeout  = time.Millisecond
+
+	// cleanupBatchSize is the number of entries removed by Cleanup within
+	// a single acquisition of the write lock.
+	cleanupBatchSize = 128
+
+	defaultAddRetries = 3
 )
 
-// ErrKeyExists is returned when the Add method generates a non-unique ID.
+// ErrKeyExists is returned when the Add method generates a non-unique key,
+// after exhausting its retries.
 var ErrKeyExists = errors.New("the key already exists")
 
 type entry struct {
 	data    []byte
 	validTo int64
+	version uint64
+
+	// cost is the weight of the entry against the byte budget.
+	cost int64
+
+	// idle is the sliding lifetime of the entry: when not zero, validTo is
+	// renewed on every Get.
+	idle time.Duration
+
+	// created and updated are the times of the first and last writes of
+	// the key; access is shared by all the versions of the entry.
+	created, updated int64
+	access           *access
+
+	// tags are the tags of the entry, set with SetTagged.
+	tags []string
 }
 
 func (e *entry) validAt(t time.Time) bool {
@@ -37,43 +64,449 @@ func (e *entry) validAt(t time.Time) bool {
 //
 // Store is safe for concurrent use.
 type Store struct {
-	mu sync.RWMutex
-	m  map[string]entry
+	mu      sync.RWMutex
+	m       map[string]entry
+	exp     *expiries
+	version uint64 // incremented on every write
+	stats   *counters
+
+	defaultTTL time.Duration
+	jitter     float64
+	newKey     func() (string, error)
+	addRetries int
+	random     io.Reader // source of the UUIDs, crypto/rand if nil
+	randomMu   sync.Mutex
+
+	cleanupInterval time.Duration
+	cleanupTimeout  time.Duration
+	onExpire        func(k string, data []byte)
+	onEvict         func(k string, data []byte, reason EvictionReason)
+	onSet           func(k string, data []byte)
+	onDelete        func(k string, data []byte)
+
+	removed   []removal  // to be notified by unlock
+	mutations []mutation // to be notified by unlock
+
+	codec        Codec
+	validateJSON bool
+	validator    func(k string, data []byte) error
+	aead         cipher.AEAD // nil unless encryption is set
+
+	aofPath string
+	aof     *aof // nil unless aofPath is set
+
+	logger      Logger
+	watchers    []*watcher
+	eventBuffer int
+	dropPolicy  DropPolicy
+	pubsub      pubsub
+
+	loader     Loader
+	flights    flights
+	staleGrace time.Duration
+
+	backing       store.Store // mirrors the writes, if set
+	behind        *writeBehind
+	flushInterval time.Duration
+	onFlushError  func(k string, err error)
+
+	snapshotPath  string
+	snapshotEvery time.Duration
+	snapshotErr   error // last error of the automatic snapshots
+
+	maxEntries int
+	maxBytes   int64
+	costFn     func(k string, data []byte) int64
+	cost       int64          // total cost of the entries, if maxBytes is set
+	policy     EvictionPolicy // nil unless a capacity is set
+	policyMu   sync.Mutex     // protects policy under the read lock
+
+	quotas  map[string]*quota              // by bucket name, set WithBucketQuota
+	indexes map[string]*index              // by name, set WithIndex
+	tagged  map[string]map[string]struct{} // keys by tag
+
+	frozen bool // set by Freeze
+
+	clock Clock
 
 	close func()
 }
 
-// New initialises the map underlying Store.
-func New() *Store {
+// New initialises the map underlying Store, applying the given options.
+func New(opts ...Option) *Store {
 	s := &Store{
-		m: make(map[string]entry),
+		m:          make(map[string]entry),
+		exp:        newExpiries(),
+		stats:      new(counters),
+		addRetries: defaultAddRetries,
+		codec:      JSON,
+		clock:      realClock{},
+
+		cleanupInterval: cleanupInterval,
+		cleanupTimeout:  cleanupTimeout,
+		eventBuffer:     defaultEventBuffer,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.newKey == nil {
+		s.newKey = s.newUUID
+	}
+	if s.maxEntries <= 0 && s.maxBytes <= 0 {
+		s.policy = nil
+	} else if s.policy == nil {
+		s.policy = NewLRU()
+	}
+	if s.costFn == nil {
+		s.costFn = defaultCost
+	}
+	// Restoring is not writing: the backing store already holds what was
+	// written, and possibly more recent values.
+	backing, behind := s.backing, s.behind
+	s.backing, s.behind = nil, nil
+	if s.aofPath != "" {
+		s.openAOF(s.aofPath)
+	}
+	if s.snapshotPath != "" {
+		s.snapshotErr = s.loadSnapshot(s.snapshotPath)
+	}
+	s.backing, s.behind = backing, behind
+
+	var stops []func()
+	if s.cleanupInterval > 0 {
+		stops = append(stops, start(s.clock, s.Cleanup, s.cleanupTimeout, s.cleanupInterval))
+	}
+	if s.snapshotPath != "" && s.snapshotEvery > 0 {
+		stops = append(stops, start(s.clock, s.autoSnapshot, s.snapshotEvery, s.snapshotEvery))
+	}
+	if s.behind != nil {
+		s.behind.onError = s.onFlushError
+		stops = append(stops, s.behind.start(s.flushInterval))
+	}
+	s.close = func() {
+		for _, stop := range stops {
+			stop()
+		}
 	}
-	s.close = start(s.Cleanup, cleanupTimeout, cleanupInterval)
 	return s
 }
 
+// newUUID returns a random UUIDv4, read from the random source of the
+// Store.
+func (s *Store) newUUID() (string, error) {
+	if s.random == nil {
+		u, err := uuid.NewRandom()
+		if err != nil {
+			return "", err
+		}
+		return u.String(), nil
+	}
+
+	s.randomMu.Lock()
+	defer s.randomMu.Unlock()
+
+	u, err := uuid.NewRandomFromReader(s.random)
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
+
+// defaultCost is the cost of an entry when no cost function is set: the
+// length of its key and value.
+func defaultCost(k string, data []byte) int64 {
+	return int64(len(k) + len(data))
+}
+
+// newEntry returns an entry for the key k holding b, sealed if encryption is
+// set, and expiring after the default TTL of the bucket of k or of the
+// Store, if one is set.
+func (s *Store) newEntry(k string, b []byte) entry {
+	e := entry{data: s.seal(b)}
+	ttl := s.defaultTTL
+	if q := s.quotaOf(k); q != nil && q.DefaultTTL > 0 {
+		ttl = q.DefaultTTL
+	}
+	if ttl > 0 {
+		e.validTo = s.clock.Now().Add(s.jittered(ttl)).UnixNano()
+	}
+	return e
+}
+
+// jittered returns d randomly spread by the jitter fraction, if set.
+func (s *Store) jittered(d time.Duration) time.Duration {
+	if s.jitter == 0 {
+		return d
+	}
+	return d + time.Duration((2*rand.Float64()-1)*s.jitter*float64(d))
+}
+
+// put stores e under the key k, stamping it with a new version, and mirrors
+// the write. The caller must hold the write lock.
+func (s *Store) put(k string, e entry) {
+	s.assign(k, e)
+	s.mirror(k, &e)
+}
+
+// assign is put, without mirroring the write.
+func (s *Store) assign(k string, e entry) {
+	now := s.clock.Now()
+	e.created, e.access = 0, nil
+	if old, ok := s.m[k]; ok {
+		s.removing(k, old, Replaced)
+		s.cost -= old.cost
+		s.charge(k, old, -1)
+		s.untag(k, old.tags)
+		if old.validAt(now) {
+			e.created, e.access = old.created, old.access
+		}
+	}
+	if e.access == nil {
+		e.created, e.access = now.UnixNano(), new(access)
+	}
+	e.updated = now.UnixNano()
+
+	if s.maxBytes > 0 {
+		e.cost = s.costFn(k, e.data)
+		s.cost += e.cost
+	}
+
+	s.charge(k, e, 1)
+
+	s.version++
+	e.version = s.version
+	s.m[k] = e
+	if len(s.indexes) > 0 {
+		s.reindex(k, e)
+	}
+	s.tag(k, e.tags)
+	s.mutated(k, e, false)
+	atomic.AddUint64(&s.stats.sets, 1)
+	if len(s.watchers) > 0 {
+		s.notify(OpSet, k, now)
+	}
+	s.exp.set(k, e.validTo)
+	s.logSet(k, e)
+
+	if s.policy != nil {
+		s.policy.OnSet(k)
+		for s.overCapacity() {
+			victim, ok := s.policy.Victim()
+			if !ok {
+				break
+			}
+			if _, ok := s.m[victim]; !ok {
+				// The policy holds a key unknown to the Store: drop
+				// it, rather than choosing it again forever.
+				s.policy.OnRemove(victim)
+				continue
+			}
+			s.remove(victim, Evicted)
+		}
+	}
+}
+
+// remove deletes the entry corresponding to the key, if present, for the
+// given reason, and mirrors the deletion if explicit. Evictions and
+// expirations are not mirrored. The caller must hold the write lock.
+func (s *Store) remove(k string, reason EvictionReason) {
+	if s.drop(k, reason) == Deleted {
+		s.mirror(k, nil)
+	}
+}
+
+// drop is remove, without mirroring the deletion. It returns the actual
+// reason of the removal, or zero if the key is not present.
+func (s *Store) drop(k string, reason EvictionReason) EvictionReason {
+	e, ok := s.m[k]
+	if !ok {
+		return 0
+	}
+
+	now := s.clock.Now()
+	if !e.validAt(now) {
+		reason = Expired
+	}
+	s.removing(k, e, reason)
+	if reason == Deleted {
+		s.mutated(k, e, true)
+	}
+	s.stats.removed(reason)
+	if len(s.watchers) > 0 {
+		s.notify(removalOp(reason), k, now)
+	}
+	s.cost -= e.cost
+	s.charge(k, e, -1)
+	delete(s.m, k)
+	s.unindex(k)
+	s.untag(k, e.tags)
+	s.exp.unset(k)
+	s.logDel(k)
+	if s.policy != nil {
+		s.policy.OnRemove(k)
+	}
+	return reason
+}
+
+// overCapacity reports whether the Store holds more entries or bytes than
+// allowed. The caller must hold the lock.
+func (s *Store) overCapacity() bool {
+	return (s.maxEntries > 0 && len(s.m) > s.maxEntries) ||
+		(s.maxBytes > 0 && s.cost > s.maxBytes)
+}
+
+// accessed records a read of the key for the eviction policy. The caller
+// must hold at least the read lock.
+func (s *Store) accessed(k string) {
+	if s.policy == nil {
+		return
+	}
+
+	s.policyMu.Lock()
+	defer s.policyMu.Unlock()
+	s.policy.OnGet(k)
+}
+
+// removing records the removal of e, to be notified by unlock. Entries
+// found expired are reported as Expired regardless of reason.
+func (s *Store) removing(k string, e entry, reason EvictionReason) {
+	if s.onEvict == nil && s.onExpire == nil && s.logger == nil {
+		return
+	}
+	if !e.validAt(s.clock.Now()) {
+		reason = Expired
+	}
+	data, _ := s.value(e)
+	s.removed = append(s.removed, removal{key: k, data: data, reason: reason})
+}
+
+// unlock flushes the append-only log, if any, releases the write lock, then
+// runs the callbacks for the entries written and removed while holding it.
+func (s *Store) unlock() {
+	s.flushAOF()
+
+	removed, mutations := s.removed, s.mutations
+	s.removed, s.mutations = nil, nil
+	s.mu.Unlock()
+
+	for _, m := range mutations {
+		if m.deleted {
+			s.onDelete(m.key, m.data)
+		} else {
+			s.onSet(m.key, m.data)
+		}
+	}
+
+	for _, r := range removed {
+		if r.reason == Evicted && s.logger != nil {
+			s.logger.Log(EventEvict, r.key)
+		}
+		if r.reason == Expired && s.onExpire != nil {
+			s.onExpire(r.key, r.data)
+		}
+		if s.onEvict != nil {
+			s.onEvict(r.key, r.data, r.reason)
+		}
+	}
+}
+
 // Get returns the value corresponding the key, and a nil error.
-// If no match is found, returns (false, nil).
+// If no match is found, returns (false, nil). If configured WithLoader, a
+// missing key is loaded and stored before being returned; if configured
+// WithStaleWhileRevalidate, an entry expired within the grace period is
+// returned while being reloaded in the background.
 func (s *Store) Get(ctx context.Context, k string, v json.Unmarshaler) (bool, error) {
+	return s.get(ctx, k, v, s.loader)
+}
+
+// get is Get, loading the missing keys with loader if not nil.
+func (s *Store) get(ctx context.Context, k string, v json.Unmarshaler, loader Loader) (bool, error) {
 	select {
 	case <-ctx.Done():
 		return false, ctx.Err()
 	default:
 	}
+	defer s.stats.get.since(time.Now())
+
+	now := s.clock.Now()
 
 	s.mu.RLock()
-	defer s.mu.RUnlock()
+	e, found := s.m[k]
+	ok := found && e.validAt(now)
+	if ok {
+		s.accessed(k)
+		e.access.touch(now)
+	}
+	s.mu.RUnlock()
+	s.stats.hit(ok)
 
-	e, ok := s.m[k]
-	if !ok || !e.validAt(time.Now()) {
-		return false, nil
+	if !ok {
+		if loader == nil {
+			return false, nil
+		}
+		if !found || !e.validAt(now.Add(-s.staleGrace)) {
+			return s.load(ctx, k, v, loader)
+		}
+		go s.revalidate(k, loader)
 	}
 
-	return true, v.UnmarshalJSON(e.data)
+	if e.idle != 0 {
+		s.slide(k, e.version, now)
+	}
+
+	data, err := s.value(e)
+	if err != nil {
+		return true, err
+	}
+	return true, v.UnmarshalJSON(data)
 }
 
-// GetAll returns all values. Error is non-nil if the context is Done.
+// Exists reports whether a valid entry corresponds to the key, without
+// unmarshaling its value.
+// Err is non-nil if the context is Done.
+func (s *Store) Exists(ctx context.Context, k string) (bool, error) {
+	select {
+	case <-ctx.Done():
+		return false, ctx.Err()
+	default:
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	e, ok := s.m[k]
+	return ok && e.validAt(s.clock.Now()), nil
+}
+
+// GetAll returns all values. Error is non-nil if the context is Done, even
+// while the values are being unmarshaled, or if unmarshaling fails.
 func (s *Store) GetAll(ctx context.Context, c store.Collection) error {
+	return s.GetWhere(ctx, func(string, []byte) bool { return true }, c)
+}
+
+// GetWhere returns the values for which pred returns true. The data passed
+// to pred must not be modified.
+// Error is non-nil if the context is Done or if unmarshaling fails.
+func (s *Store) GetWhere(ctx context.Context, pred func(k string, data []byte) bool, c store.Collection) error {
+	return s.getWhere(ctx, pred, func(string) json.Unmarshaler { return c.New() })
+}
+
+// KeyedCollection is like store.Collection, except the key of each value is
+// passed to New.
+type KeyedCollection interface {
+	New(k string) json.Unmarshaler
+}
+
+// GetAllKeyed returns all values along with their keys.
+// Error is non-nil if the context is Done or if unmarshaling fails.
+func (s *Store) GetAllKeyed(ctx context.Context, c KeyedCollection) error {
+	return s.getWhere(ctx, func(string, []byte) bool { return true }, c.New)
+}
+
+// getWhere unmarshals the values for which pred returns true into the
+// Unmarshalers returned by newFn. The context is checked before each entry,
+// so that a cancelled call stops without visiting the rest of the Store.
+func (s *Store) getWhere(ctx context.Context, pred func(k string, data []byte) bool, newFn func(k string) json.Unmarshaler) error {
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
@@ -83,11 +516,25 @@ func (s *Store) GetAll(ctx context.Context, c store.Collection) error {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	now := time.Now()
+	now := s.clock.Now()
+
+	for k, e := range s.m {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		if !e.validAt(now) {
+			continue
+		}
 
-	for _, e := range s.m {
-		if e.validAt(now) {
-			if err := c.New().UnmarshalJSON(e.data); err != nil {
+		data, err := s.value(e)
+		if err != nil {
+			return err
+		}
+		if pred(k, data) {
+			if err := newFn(k).UnmarshalJSON(data); err != nil {
 				return err
 			}
 		}
@@ -96,96 +543,177 @@ func (s *Store) GetAll(ctx context.Context, c store.Collection) error {
 	return nil
 }
 
-// Add persists a new object and returns its unique UUIDv4 key.
+// Add persists a new object and returns its unique key, generated as a
+// UUIDv4 unless configured otherwise with WithKeyGenerator.
+// The entry expires after the default TTL, if one is set.
 // Err is non-nil in case of failure.
 func (s *Store) Add(ctx context.Context, v json.Marshaler) (string, error) {
+	return s.add(ctx, v, time.Time{})
+}
+
+// AddWithTimeout persists a new object and returns its unique key, like Add.
+// The new entry will clear after timeout, spread by the TTL jitter if set.
+// The lifespan starts when this function is called.
+// Err is non-nil in case of failure.
+func (s *Store) AddWithTimeout(ctx context.Context, v json.Marshaler, timeout time.Duration) (string, error) {
+	return s.add(ctx, v, s.clock.Now().Add(s.jittered(timeout)))
+}
+
+// AddWithDeadline persists a new object and returns its unique key, like Add.
+// The new entry will clear after deadline.
+// Err is non-nil in case of failure.
+func (s *Store) AddWithDeadline(ctx context.Context, v json.Marshaler, deadline time.Time) (string, error) {
+	return s.add(ctx, v, deadline)
+}
+
+// add persists a new object expiring at deadline. A zero deadline means the
+// default TTL.
+func (s *Store) add(ctx context.Context, v json.Marshaler, deadline time.Time) (string, error) {
 	select {
 	case <-ctx.Done():
 		return "", ctx.Err()
 	default:
 	}
 
-	b, err := v.MarshalJSON()
+	b, err := s.marshal("", v)
 	if err != nil {
 		return "", err
 	}
 
-	k := uuid.New().String()
+	for i := 0; i <= s.addRetries; i++ {
+		k, err := s.newKey()
+		if err != nil {
+			return "", err
+		}
+		ok, err := s.create(ctx, k, b, deadline)
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			return k, nil
+		}
+	}
+	return "", ErrKeyExists
+}
 
+// create assigns the value b to k, expiring at deadline, unless a valid
+// entry corresponds to k already. A zero deadline means the default TTL.
+// The write is mirrored like Set. Returns false if k is taken.
+func (s *Store) create(ctx context.Context, k string, b []byte, deadline time.Time) (bool, error) {
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	defer s.unlock()
 	select {
 	case <-ctx.Done():
-		return "", ctx.Err()
+		return false, ctx.Err()
 	default:
 	}
+	if s.frozen {
+		return false, ErrReadOnly
+	}
 
-	if _, ok := s.m[k]; ok {
-		return "", ErrKeyExists
+	if e, ok := s.m[k]; ok && e.validAt(s.clock.Now()) {
+		return false, nil
 	}
 
-	s.m[k] = entry{data: b}
-	return k, nil
+	e := s.newEntry(k, b)
+	if !deadline.IsZero() {
+		e.validTo = deadline.UnixNano()
+	}
+	if err := s.admit(k, e); err != nil {
+		return false, err
+	}
+	if err := s.mirrorSet(ctx, k, b, deadline); err != nil {
+		return false, err
+	}
+	s.assign(k, e)
+	return true, nil
 }
 
 // Set assigns the given value to the given key, possibly overwriting.
-// The returned error is not nil if the context is Done.
+// The entry expires after the default TTL, if one is set.
+// The returned error is not nil if the context is Done, or if the write
+// would exceed the quota of the bucket of the key.
 func (s *Store) Set(ctx context.Context, k string, v json.Marshaler) error {
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
 	default:
 	}
+	defer s.stats.set.since(time.Now())
 
-	b, err := v.MarshalJSON()
+	b, err := s.marshal(k, v)
 	if err != nil {
 		return err
 	}
 
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	defer s.unlock()
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
 	default:
 	}
+	if s.frozen {
+		return ErrReadOnly
+	}
 
-	s.m[k] = entry{data: b}
+	e := s.newEntry(k, b)
+	if err := s.admit(k, e); err != nil {
+		return err
+	}
+	if err := s.mirrorSet(ctx, k, b, time.Time{}); err != nil {
+		return err
+	}
+	s.assign(k, e)
 	return nil
 }
 
 // SetWithTimeout assigns the given value to the given key, possibly
 // overwriting.
-// The assigned key will clear after timeout. The lifespan starts when this
-// function is called.
+// The assigned key will clear after timeout, spread by the TTL jitter if
+// set. The lifespan starts when this function is called.
 func (s *Store) SetWithTimeout(ctx context.Context, k string, v json.Marshaler, timeout time.Duration) error {
-	return s.SetWithDeadline(ctx, k, v, time.Now().Add(timeout))
+	return s.SetWithDeadline(ctx, k, v, s.clock.Now().Add(s.jittered(timeout)))
 }
 
 // SetWithDeadline assigns the given value to the given key, possibly
 // overwriting.
 // The assigned key will clear after deadline.
+// The returned error is not nil if the context is Done, or if the write
+// would exceed the quota of the bucket of the key.
 func (s *Store) SetWithDeadline(ctx context.Context, k string, v json.Marshaler, deadline time.Time) error {
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
 	default:
 	}
+	defer s.stats.set.since(time.Now())
 
-	b, err := v.MarshalJSON()
+	b, err := s.marshal(k, v)
 	if err != nil {
 		return err
 	}
 
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	defer s.unlock()
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
 	default:
 	}
+	if s.frozen {
+		return ErrReadOnly
+	}
 
-	s.m[k] = entry{data: b, validTo: deadline.UnixNano()}
+	e := s.newEntry(k, b)
+	e.validTo = deadline.UnixNano()
+	if err := s.admit(k, e); err != nil {
+		return err
+	}
+	if err := s.mirrorSet(ctx, k, b, deadline); err != nil {
+		return err
+	}
+	s.assign(k, e)
 	return nil
 }
 
@@ -197,34 +725,64 @@ func (s *Store) Delete(ctx context.Context, k string) (bool, error) {
 		return false, ctx.Err()
 	default:
 	}
+	defer s.stats.del.since(time.Now())
 
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	defer s.unlock()
 
 	select {
 	case <-ctx.Done():
 		return false, ctx.Err()
 	default:
 	}
+	if s.frozen {
+		return false, ErrReadOnly
+	}
+
+	mirrored, err := s.mirrorDelete(ctx, k)
+	if err != nil {
+		return false, err
+	}
 
 	_, ok := s.m[k]
 
-	delete(s.m, k)
-	return ok, nil
+	s.drop(k, Deleted)
+	return ok || mirrored, nil
 }
 
-// Ping always returns nil if the context is not Done.
+// Ping returns nil if the context is not Done, and the Store is healthy: if
+// configured WithAOF, Ping returns the first error of the append-only log;
+// if configured WithAutoSnapshot, the error of the last snapshot.
 func (s *Store) Ping(ctx context.Context) error {
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
 	default:
-		return nil
 	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.aof != nil && s.aof.err != nil {
+		return s.aof.err
+	}
+	return s.snapshotErr
 }
 
-// Close releases the resources associated with the Store.
+// Close releases the resources associated with the Store. If configured
+// WithAutoSnapshot, it takes a last snapshot; if configured WithAOF, it
+// flushes and closes the log. It returns the first error of either.
 func (s *Store) Close() error {
 	s.close()
-	return nil
+
+	var err error
+	if s.snapshotPath != "" {
+		err = s.saveSnapshot(context.Background(), s.snapshotPath)
+	}
+	if s.aof != nil {
+		if aofErr := s.closeAOF(); err == nil {
+			err = aofErr
+		}
+	}
+	return err
 }
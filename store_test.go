@@ -2,6 +2,7 @@ package mem_test
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"testing"
 	"time"
@@ -20,6 +21,14 @@ func (s String) MarshalJSON() (data []byte, err error) {
 	return []byte(s), nil
 }
 
+// collection implements store.Collection.
+type collection []String
+
+func (c *collection) New() json.Unmarshaler {
+	*c = append(*c, "")
+	return &(*c)[len(*c)-1]
+}
+
 func TestStore(t *testing.T) {
 	type checkFunc func(*mem.Store) error
 	checks := func(fns ...checkFunc) []checkFunc { return fns }
@@ -191,3 +200,135 @@ func TestAdd(t *testing.T) {
 		}
 	})
 }
+
+func TestGetWhere(t *testing.T) {
+	s := mem.New()
+	defer s.Close()
+
+	for k, v := range map[string]String{"a": "1", "b": "22", "c": "33"} {
+		if err := s.Set(context.Background(), k, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var c collection
+	err := s.GetWhere(context.Background(), func(k string, data []byte) bool {
+		return len(data) == 2 && k != "c"
+	}, &c)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(c) != 1 || c[0] != "22" {
+		t.Errorf("expected [%q], found %q", "22", c)
+	}
+}
+
+// keyedCollection implements mem.KeyedCollection.
+type keyedCollection map[string]*String
+
+func (c keyedCollection) New(k string) json.Unmarshaler {
+	v := new(String)
+	c[k] = v
+	return v
+}
+
+func TestGetAllKeyed(t *testing.T) {
+	s := mem.New()
+	defer s.Close()
+
+	want := map[string]String{"a": "1", "b": "2"}
+	for k, v := range want {
+		if err := s.Set(context.Background(), k, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c := make(keyedCollection)
+	if err := s.GetAllKeyed(context.Background(), c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(c) != len(want) {
+		t.Fatalf("expected %d values, found %d", len(want), len(c))
+	}
+	for k, v := range want {
+		if have, ok := c[k]; !ok || *have != v {
+			t.Errorf("expected %q for key %q, found %v", v, k, have)
+		}
+	}
+}
+
+// cancelingCollection cancels its context when the first value is
+// unmarshaled.
+type cancelingCollection struct {
+	collection
+	cancel context.CancelFunc
+}
+
+func (c *cancelingCollection) New() json.Unmarshaler {
+	c.cancel()
+	return c.collection.New()
+}
+
+func TestGetAllCancel(t *testing.T) {
+	s := mem.New()
+	defer s.Close()
+
+	for _, k := range []string{"a", "b", "c"} {
+		if err := s.Set(context.Background(), k, String(k)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &cancelingCollection{cancel: cancel}
+	if err := s.GetAll(ctx, c); err != context.Canceled {
+		t.Errorf("expected error %v, found %v", context.Canceled, err)
+	}
+	if len(c.collection) != 1 {
+		t.Errorf("expected the iteration to stop after 1 value, found %d", len(c.collection))
+	}
+}
+
+func TestExists(t *testing.T) {
+	s := mem.New()
+	defer s.Close()
+
+	if err := s.Set(context.Background(), "key", String("value")); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetWithTimeout(context.Background(), "expired", String("value"), -time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	for k, want := range map[string]bool{"key": true, "expired": false, "unset key": false} {
+		ok, err := s.Exists(context.Background(), k)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if ok != want {
+			t.Errorf("expected Exists(%q) to be %v, found %v", k, want, ok)
+		}
+	}
+}
+
+func TestAddWithTimeout(t *testing.T) {
+	s := mem.New()
+	defer s.Close()
+
+	k, err := s.AddWithTimeout(context.Background(), String("ticket"), time.Millisecond)
+	if err != nil {
+		t.Fatalf("adding: %v", err)
+	}
+
+	var got String
+	if ok, _ := s.Get(context.Background(), k, &got); !ok {
+		t.Fatal("value expected, not found")
+	}
+
+	time.Sleep(2 * time.Millisecond)
+	if ok, _ := s.Get(context.Background(), k, &got); ok {
+		t.Error("expected the value to expire")
+	}
+}
@@ -0,0 +1,206 @@
+// Package storetest provides a conformance suite for implementations of
+// store.Store, run against mem and usable with any other gokv backend.
+package storetest
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/gokv/store"
+)
+
+// value is a JSON string.
+type value string
+
+func (v *value) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	*v = value(s)
+	return nil
+}
+
+func (v value) MarshalJSON() ([]byte, error) {
+	return json.Marshal(string(v))
+}
+
+// collection implements store.Collection.
+type collection []value
+
+func (c *collection) New() json.Unmarshaler {
+	*c = append(*c, "")
+	return &(*c)[len(*c)-1]
+}
+
+// TestStore runs the conformance suite against the stores returned by
+// newStore: each subtest uses a new, empty store, and closes it when done.
+func TestStore(t *testing.T, newStore func() store.Store) {
+	ctx := context.Background()
+
+	run := func(name string, fn func(t *testing.T, s store.Store)) {
+		t.Run(name, func(t *testing.T) {
+			s := newStore()
+			defer func() {
+				if err := s.Close(); err != nil {
+					t.Errorf("closing: %v", err)
+				}
+			}()
+			fn(t, s)
+		})
+	}
+
+	run("Ping", func(t *testing.T, s store.Store) {
+		if err := s.Ping(ctx); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	run("Get missing", func(t *testing.T, s store.Store) {
+		var v value
+		ok, err := s.Get(ctx, "missing", &v)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if ok {
+			t.Errorf("expected no value, found %q", v)
+		}
+	})
+
+	run("Set and Get", func(t *testing.T, s store.Store) {
+		if err := s.Set(ctx, "key", value("first")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := s.Set(ctx, "key", value("second")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		var v value
+		if ok, err := s.Get(ctx, "key", &v); err != nil || !ok || v != "second" {
+			t.Errorf("expected %q, found %q (found: %v, err: %v)", "second", v, ok, err)
+		}
+	})
+
+	run("Add", func(t *testing.T, s store.Store) {
+		k1, err := s.Add(ctx, value("first"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		k2, err := s.Add(ctx, value("second"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if k1 == "" || k1 == k2 {
+			t.Errorf("expected two unique keys, found %q and %q", k1, k2)
+		}
+
+		var v value
+		if ok, err := s.Get(ctx, k1, &v); err != nil || !ok || v != "first" {
+			t.Errorf("expected %q, found %q (found: %v, err: %v)", "first", v, ok, err)
+		}
+	})
+
+	run("Delete", func(t *testing.T, s store.Store) {
+		s.Set(ctx, "key", value("value"))
+
+		if ok, err := s.Delete(ctx, "key"); err != nil || !ok {
+			t.Errorf("expected a deletion, found (%v, %v)", ok, err)
+		}
+		if ok, _ := s.Get(ctx, "key", new(value)); ok {
+			t.Error("expected the key to be deleted")
+		}
+		if ok, err := s.Delete(ctx, "key"); err != nil || ok {
+			t.Errorf("expected no deletion, found (%v, %v)", ok, err)
+		}
+	})
+
+	run("GetAll", func(t *testing.T, s store.Store) {
+		for _, v := range []value{"a", "b", "c"} {
+			if err := s.Set(ctx, string(v), v); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+
+		var c collection
+		if err := s.GetAll(ctx, &c); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		sort.Slice(c, func(i, j int) bool { return c[i] < c[j] })
+		if len(c) != 3 || c[0] != "a" || c[1] != "b" || c[2] != "c" {
+			t.Errorf("expected %q, found %q", []value{"a", "b", "c"}, c)
+		}
+	})
+
+	run("SetWithTimeout", func(t *testing.T, s store.Store) {
+		if err := s.SetWithTimeout(ctx, "volatile", value("value"), time.Minute); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := s.SetWithTimeout(ctx, "expired", value("value"), -time.Second); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if ok, _ := s.Get(ctx, "volatile", new(value)); !ok {
+			t.Error("expected the value not to be expired yet")
+		}
+		if ok, _ := s.Get(ctx, "expired", new(value)); ok {
+			t.Error("expected the value to be expired")
+		}
+	})
+
+	run("SetWithDeadline", func(t *testing.T, s store.Store) {
+		now := time.Now()
+		if err := s.SetWithDeadline(ctx, "volatile", value("value"), now.Add(time.Minute)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := s.SetWithDeadline(ctx, "expired", value("value"), now.Add(-time.Second)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if ok, _ := s.Get(ctx, "volatile", new(value)); !ok {
+			t.Error("expected the value not to be expired yet")
+		}
+		if ok, _ := s.Get(ctx, "expired", new(value)); ok {
+			t.Error("expected the value to be expired")
+		}
+
+		var c collection
+		s.GetAll(ctx, &c)
+		if len(c) != 1 {
+			t.Errorf("expected GetAll to skip the expired value, found %q", c)
+		}
+	})
+
+	run("Set clears the deadline", func(t *testing.T, s store.Store) {
+		s.SetWithTimeout(ctx, "key", value("volatile"), time.Minute)
+		s.Set(ctx, "key", value("permanent"))
+
+		var v value
+		if ok, _ := s.Get(ctx, "key", &v); !ok || v != "permanent" {
+			t.Errorf("expected %q, found %q", "permanent", v)
+		}
+	})
+
+	run("context Done", func(t *testing.T, s store.Store) {
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		if _, err := s.Get(ctx, "key", new(value)); err == nil {
+			t.Error("Get: expected an error")
+		}
+		if err := s.Set(ctx, "key", value("value")); err == nil {
+			t.Error("Set: expected an error")
+		}
+		if _, err := s.Add(ctx, value("value")); err == nil {
+			t.Error("Add: expected an error")
+		}
+		if _, err := s.Delete(ctx, "key"); err == nil {
+			t.Error("Delete: expected an error")
+		}
+		if err := s.Ping(ctx); err == nil {
+			t.Error("Ping: expected an error")
+		}
+	})
+}
@@ -0,0 +1,21 @@
+package storetest_test
+
+import (
+	"testing"
+
+	"github.com/gokv/mem"
+	"github.com/gokv/mem/storetest"
+	"github.com/gokv/store"
+)
+
+func TestMem(t *testing.T) {
+	storetest.TestStore(t, func() store.Store { return mem.New() })
+}
+
+func TestSharded(t *testing.T) {
+	storetest.TestStore(t, func() store.Store { return mem.NewSharded(4) })
+}
+
+func TestBucket(t *testing.T) {
+	storetest.TestStore(t, func() store.Store { return mem.New(mem.WithCleanupInterval(0)).Bucket("bucket") })
+}
@@ -0,0 +1,76 @@
+package mem
+
+import "context"
+
+// streamBatchSize is the number of values copied by Stream within a single
+// acquisition of the read lock.
+const streamBatchSize = 128
+
+// KV is an entry sent by Stream.
+type KV struct {
+	Key   string
+	Value []byte // a copy, which the receiver may modify
+}
+
+// Stream sends the valid entries on the returned channel, which is closed
+// when all of them are sent or the context is Done. The keys are listed
+// under the read lock, then the values are copied streamBatchSize at a time,
+// releasing the lock between batches: entries deleted in the meantime are
+// skipped, and entries updated in the meantime are sent with their new
+// value. Entries whose value cannot be decrypted are skipped.
+// Err is non-nil if the context is Done.
+func (s *Store) Stream(ctx context.Context) (<-chan KV, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	keys, err := s.keysMatching(ctx, func(string) bool { return true })
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan KV, streamBatchSize)
+	go func() {
+		defer close(ch)
+		for len(keys) > 0 {
+			n := streamBatchSize
+			if n > len(keys) {
+				n = len(keys)
+			}
+			for _, kv := range s.copyValues(keys[:n]) {
+				select {
+				case ch <- kv:
+				case <-ctx.Done():
+					return
+				}
+			}
+			keys = keys[n:]
+		}
+	}()
+	return ch, nil
+}
+
+// copyValues returns the valid entries of the given keys, under the read
+// lock.
+func (s *Store) copyValues(keys []string) []KV {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := s.clock.Now()
+
+	kvs := make([]KV, 0, len(keys))
+	for _, k := range keys {
+		e, ok := s.m[k]
+		if !ok || !e.validAt(now) {
+			continue
+		}
+		data, err := s.value(e)
+		if err != nil {
+			continue
+		}
+		kvs = append(kvs, KV{Key: k, Value: append([]byte(nil), data...)})
+	}
+	return kvs
+}
@@ -0,0 +1,66 @@
+package mem_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/gokv/mem"
+)
+
+func TestStream(t *testing.T) {
+	ctx := context.Background()
+
+	s := mem.New()
+	defer s.Close()
+
+	for i := 0; i < 300; i++ {
+		k := fmt.Sprintf("key%d", i)
+		s.Set(ctx, k, String(`"`+k+`"`))
+	}
+
+	ch, err := s.Stream(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	seen := make(map[string]bool)
+	for kv := range ch {
+		if want := `"` + kv.Key + `"`; string(kv.Value) != want {
+			t.Errorf("expected %q, found %q", want, kv.Value)
+		}
+		seen[kv.Key] = true
+	}
+	if len(seen) != 300 {
+		t.Errorf("expected 300 entries, found %d", len(seen))
+	}
+
+	ch, _ = s.Stream(ctx)
+	for kv := range ch {
+		for i := range kv.Value {
+			kv.Value[i] = 'x'
+		}
+	}
+	var v String
+	if s.Get(ctx, "key0", &v); v != `"key0"` {
+		t.Errorf("expected the stored value to be left unchanged, found %q", v)
+	}
+
+	t.Run("stops when the context is Done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		ch, err := s.Stream(ctx)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		<-ch
+		cancel()
+
+		var n int
+		for range ch {
+			n++
+		}
+		if n >= 299 {
+			t.Errorf("expected the stream to stop early, found %d more entries", n)
+		}
+	})
+}
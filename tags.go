@@ -0,0 +1,142 @@
+package mem
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/gokv/store"
+)
+
+// SetTagged assigns the given value to the given key, possibly overwriting,
+// and tags the entry with the given tags, so that it can be found by
+// GetByTag and removed by DeleteByTag. The tags belong to the entry: they
+// are dropped when the key is written again without them.
+// The entry expires after the default TTL, if one is set.
+// The returned error is not nil if the context is Done, or if the write
+// would exceed the quota of the bucket of the key.
+func (s *Store) SetTagged(ctx context.Context, k string, v json.Marshaler, tags ...string) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+	defer s.stats.set.since(time.Now())
+
+	b, err := s.marshal(k, v)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.unlock()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+	if s.frozen {
+		return ErrReadOnly
+	}
+
+	e := s.newEntry(k, b)
+	e.tags = append([]string(nil), tags...)
+	if err := s.admit(k, e); err != nil {
+		return err
+	}
+	if err := s.mirrorSet(ctx, k, b, time.Time{}); err != nil {
+		return err
+	}
+	s.assign(k, e)
+	return nil
+}
+
+// GetByTag returns the values of the entries tagged with tag.
+// Err is non-nil if the context is Done or if unmarshaling fails.
+func (s *Store) GetByTag(ctx context.Context, tag string, c store.Collection) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := s.clock.Now()
+	for k := range s.tagged[tag] {
+		e := s.m[k]
+		if !e.validAt(now) {
+			continue
+		}
+
+		data, err := s.value(e)
+		if err != nil {
+			return err
+		}
+		if err := c.New().UnmarshalJSON(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteByTag removes the entries tagged with tag, under a single
+// acquisition of the write lock, and returns the number of valid entries
+// removed.
+// Err is non-nil if the context is Done.
+func (s *Store) DeleteByTag(ctx context.Context, tag string) (int, error) {
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	default:
+	}
+
+	s.mu.Lock()
+	defer s.unlock()
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	default:
+	}
+	if s.frozen {
+		return 0, ErrReadOnly
+	}
+
+	now := s.clock.Now()
+	var n int
+	for k := range s.tagged[tag] {
+		if e := s.m[k]; e.validAt(now) {
+			n++
+		}
+		s.remove(k, Deleted)
+	}
+	return n, nil
+}
+
+// tag adds the key k to the reverse index of each of tags. The caller must
+// hold the write lock.
+func (s *Store) tag(k string, tags []string) {
+	for _, t := range tags {
+		if s.tagged == nil {
+			s.tagged = make(map[string]map[string]struct{})
+		}
+		keys, ok := s.tagged[t]
+		if !ok {
+			keys = make(map[string]struct{})
+			s.tagged[t] = keys
+		}
+		keys[k] = struct{}{}
+	}
+}
+
+// untag removes the key k from the reverse index of each of tags. The caller
+// must hold the write lock.
+func (s *Store) untag(k string, tags []string) {
+	for _, t := range tags {
+		delete(s.tagged[t], k)
+		if len(s.tagged[t]) == 0 {
+			delete(s.tagged, t)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package mem_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gokv/mem"
+)
+
+func TestTags(t *testing.T) {
+	ctx := context.Background()
+
+	s := mem.New()
+	defer s.Close()
+
+	s.SetTagged(ctx, "page:1", String(`"one"`), "product:123", "category:4")
+	s.SetTagged(ctx, "page:2", String(`"two"`), "product:123")
+	s.SetTagged(ctx, "page:3", String(`"three"`), "category:4")
+
+	var c collection
+	if err := s.GetByTag(ctx, "product:123", &c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(c) != 2 {
+		t.Errorf("expected 2 values, found %q", c)
+	}
+
+	n, err := s.DeleteByTag(ctx, "product:123")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("expected 2 removals, found %d", n)
+	}
+
+	c = nil
+	s.GetByTag(ctx, "category:4", &c)
+	if len(c) != 1 || c[0] != `"three"` {
+		t.Errorf("expected %q, found %q", []string{`"three"`}, c)
+	}
+
+	t.Run("overwriting drops the tags", func(t *testing.T) {
+		s.Set(ctx, "page:3", String(`"untagged"`))
+
+		var c collection
+		s.GetByTag(ctx, "category:4", &c)
+		if len(c) != 0 {
+			t.Errorf("expected no values, found %q", c)
+		}
+	})
+}
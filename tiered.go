@@ -0,0 +1,114 @@
+package mem
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/gokv/store"
+)
+
+// Tiered is a two-level cache: reads are served by an in-memory L1 Store,
+// and fall through to L2 on a miss; writes go to both.
+//
+// Tiered is safe for concurrent use.
+type Tiered struct {
+	l1 *Store
+	l2 store.Store
+}
+
+// NewTiered returns a Tiered store reading through l1 to l2. The L2 hits are
+// promoted into l1 with its default TTL, which should be set so that l1 does
+// not hold stale copies indefinitely.
+func NewTiered(l1 *Store, l2 store.Store) *Tiered {
+	return &Tiered{l1: l1, l2: l2}
+}
+
+// Get returns the value corresponding the key, from L1 if present, or from
+// L2, in which case it is promoted into L1.
+// If no match is found, returns (false, nil).
+func (t *Tiered) Get(ctx context.Context, k string, v json.Unmarshaler) (bool, error) {
+	if ok, err := t.l1.Get(ctx, k, v); ok || err != nil {
+		return ok, err
+	}
+
+	var b raw
+	ok, err := t.l2.Get(ctx, k, &b)
+	if !ok || err != nil {
+		return ok, err
+	}
+	if err := t.l1.Set(ctx, k, b); err != nil {
+		return true, err
+	}
+	return true, v.UnmarshalJSON(b)
+}
+
+// GetAll returns all the values of L2.
+func (t *Tiered) GetAll(ctx context.Context, c store.Collection) error {
+	return t.l2.GetAll(ctx, c)
+}
+
+// Add persists a new object in L2, caches it in L1, and returns the key
+// generated by L2.
+// Err is non-nil in case of failure.
+func (t *Tiered) Add(ctx context.Context, v json.Marshaler) (string, error) {
+	k, err := t.l2.Add(ctx, v)
+	if err != nil {
+		return "", err
+	}
+	return k, t.l1.Set(ctx, k, v)
+}
+
+// Set assigns the given value to the given key in L2, then in L1.
+// Err is non-nil if either fails.
+func (t *Tiered) Set(ctx context.Context, k string, v json.Marshaler) error {
+	if err := t.l2.Set(ctx, k, v); err != nil {
+		return err
+	}
+	return t.l1.Set(ctx, k, v)
+}
+
+// SetWithTimeout assigns the given value to the given key in L2, then in
+// L1, with the same deadline.
+// The lifespan starts when this function is called.
+func (t *Tiered) SetWithTimeout(ctx context.Context, k string, v json.Marshaler, timeout time.Duration) error {
+	return t.SetWithDeadline(ctx, k, v, t.l1.clock.Now().Add(timeout))
+}
+
+// SetWithDeadline assigns the given value to the given key in L2, then in
+// L1.
+// The assigned key will clear after deadline.
+func (t *Tiered) SetWithDeadline(ctx context.Context, k string, v json.Marshaler, deadline time.Time) error {
+	if err := t.l2.SetWithDeadline(ctx, k, v, deadline); err != nil {
+		return err
+	}
+	return t.l1.SetWithDeadline(ctx, k, v, deadline)
+}
+
+// Delete removes the corresponding entry from L2 and from L1. Returns true
+// if it was present in either.
+func (t *Tiered) Delete(ctx context.Context, k string) (bool, error) {
+	ok2, err := t.l2.Delete(ctx, k)
+	if err != nil {
+		return false, err
+	}
+	ok1, err := t.l1.Delete(ctx, k)
+	return ok1 || ok2, err
+}
+
+// Ping returns the first error of pinging L1 and L2.
+func (t *Tiered) Ping(ctx context.Context) error {
+	if err := t.l1.Ping(ctx); err != nil {
+		return err
+	}
+	return t.l2.Ping(ctx)
+}
+
+// Close closes L1 and L2, and returns the first error.
+func (t *Tiered) Close() error {
+	err := t.l1.Close()
+	if err2 := t.l2.Close(); err == nil {
+		err = err2
+	}
+	return err
+}
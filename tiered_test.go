@@ -0,0 +1,47 @@
+package mem_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gokv/mem"
+)
+
+func TestTiered(t *testing.T) {
+	ctx := context.Background()
+
+	l1, l2 := mem.New(mem.WithDefaultTTL(time.Minute)), mem.New()
+	s := mem.NewTiered(l1, l2)
+	defer s.Close()
+
+	l2.Set(ctx, "cold", String(`"from L2"`))
+
+	var v String
+	if ok, err := s.Get(ctx, "cold", &v); !ok || err != nil || v != `"from L2"` {
+		t.Errorf("expected %q, found %q (%v, %v)", `"from L2"`, v, ok, err)
+	}
+	if _, ok, _ := l1.TTL(ctx, "cold"); !ok {
+		t.Error("expected the L2 hit to be promoted with a TTL")
+	}
+
+	s.Set(ctx, "key", String(`"v"`))
+	for name, tier := range map[string]*mem.Store{"L1": l1, "L2": l2} {
+		if ok, _ := tier.Exists(ctx, "key"); !ok {
+			t.Errorf("expected the write to reach %s", name)
+		}
+	}
+
+	if ok, _ := s.Delete(ctx, "key"); !ok {
+		t.Error("expected the deletion to be reported")
+	}
+	for name, tier := range map[string]*mem.Store{"L1": l1, "L2": l2} {
+		if ok, _ := tier.Exists(ctx, "key"); ok {
+			t.Errorf("expected the deletion to reach %s", name)
+		}
+	}
+
+	if ok, err := s.Get(ctx, "missing", &v); ok || err != nil {
+		t.Errorf("expected (false, nil), found (%v, %v)", ok, err)
+	}
+}
@@ -0,0 +1,138 @@
+package mem
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrNotFound is returned by the methods operating on the lifetime of an
+// existing entry, when no valid entry corresponds to the key.
+var ErrNotFound = errors.New("the key was not found")
+
+// TTL returns the remaining lifetime of the entry corresponding to the key.
+// The returned bool is false if the entry has no deadline.
+// Err is ErrNotFound if the key is not found, or non-nil if the context is
+// Done.
+func (s *Store) TTL(ctx context.Context, k string) (time.Duration, bool, error) {
+	select {
+	case <-ctx.Done():
+		return 0, false, ctx.Err()
+	default:
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := s.clock.Now()
+
+	e, ok := s.m[k]
+	if !ok || !e.validAt(now) {
+		return 0, false, ErrNotFound
+	}
+
+	if e.validTo == 0 {
+		return 0, false, nil
+	}
+	return time.Duration(e.validTo - now.UnixNano()), true, nil
+}
+
+// Expire sets the lifetime of an existing entry to d, starting now, without
+// rewriting its value.
+// Err is ErrNotFound if the key is not found, or non-nil if the context is
+// Done.
+func (s *Store) Expire(ctx context.Context, k string, d time.Duration) error {
+	return s.ExpireAt(ctx, k, s.clock.Now().Add(d))
+}
+
+// ExpireAt sets the deadline of an existing entry, without rewriting its
+// value.
+// Err is ErrNotFound if the key is not found, or non-nil if the context is
+// Done.
+func (s *Store) ExpireAt(ctx context.Context, k string, deadline time.Time) error {
+	return s.setValidTo(ctx, k, deadline.UnixNano())
+}
+
+// Persist removes the deadline of an existing entry, making it permanent.
+// Err is ErrNotFound if the key is not found, or non-nil if the context is
+// Done.
+func (s *Store) Persist(ctx context.Context, k string) error {
+	return s.setValidTo(ctx, k, 0)
+}
+
+// Touch extends the lifetime of an existing entry so that it lasts at least
+// d from now, without rewriting its value. Unlike Expire, Touch never
+// shortens a lifetime and leaves entries without a deadline untouched.
+// Err is ErrNotFound if the key is not found, or non-nil if the context is
+// Done.
+func (s *Store) Touch(ctx context.Context, k string, d time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	s.mu.Lock()
+	defer s.unlock()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+	if s.frozen {
+		return ErrReadOnly
+	}
+
+	now := s.clock.Now()
+
+	e, ok := s.m[k]
+	if !ok || !e.validAt(now) {
+		return ErrNotFound
+	}
+
+	if validTo := now.Add(d).UnixNano(); e.validTo != 0 && validTo > e.validTo {
+		s.retime(k, validTo, e.idle)
+	}
+	return nil
+}
+
+// setValidTo sets the validTo field of an existing entry, turning a sliding
+// lifetime into a fixed one. Zero means no deadline.
+func (s *Store) setValidTo(ctx context.Context, k string, validTo int64) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	s.mu.Lock()
+	defer s.unlock()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+	if s.frozen {
+		return ErrReadOnly
+	}
+
+	e, ok := s.m[k]
+	if !ok || !e.validAt(s.clock.Now()) {
+		return ErrNotFound
+	}
+
+	s.retime(k, validTo, 0)
+	return nil
+}
+
+// retime sets the lifetime of the existing entry corresponding to the key.
+// Unlike put, the entry is not replaced: its value, version and hooks are
+// left alone, and no set is counted. The caller must hold the write lock.
+func (s *Store) retime(k string, validTo int64, idle time.Duration) {
+	e := s.m[k]
+	e.validTo, e.idle = validTo, idle
+	s.m[k] = e
+	s.exp.set(k, validTo)
+	s.logSet(k, e)
+	s.mirror(k, &e)
+}
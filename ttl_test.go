@@ -0,0 +1,159 @@
+package mem_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gokv/mem"
+)
+
+func TestTTL(t *testing.T) {
+	s := mem.New()
+	defer s.Close()
+
+	if err := s.Set(context.Background(), "persistent", String("value")); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetWithTimeout(context.Background(), "volatile", String("value"), time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("volatile", func(t *testing.T) {
+		ttl, ok, err := s.TTL(context.Background(), "volatile")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !ok {
+			t.Fatal("expected a deadline")
+		}
+		if ttl <= 0 || ttl > time.Minute {
+			t.Errorf("expected a TTL within a minute, found %v", ttl)
+		}
+	})
+
+	t.Run("persistent", func(t *testing.T) {
+		_, ok, err := s.TTL(context.Background(), "persistent")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if ok {
+			t.Error("expected no deadline")
+		}
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		if _, _, err := s.TTL(context.Background(), "unset key"); err != mem.ErrNotFound {
+			t.Errorf("expected error %v, found %v", mem.ErrNotFound, err)
+		}
+	})
+}
+
+func TestExpire(t *testing.T) {
+	s := mem.New()
+	defer s.Close()
+
+	if err := s.Set(context.Background(), "key", String("value")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Expire(context.Background(), "key", time.Minute); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok, _ := s.TTL(context.Background(), "key"); !ok {
+		t.Error("expected a deadline after Expire")
+	}
+
+	if err := s.ExpireAt(context.Background(), "key", time.Now().Add(-time.Second)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ok, _ := s.Exists(context.Background(), "key"); ok {
+		t.Error("expected the key to be expired")
+	}
+
+	if err := s.Expire(context.Background(), "key", time.Minute); err != mem.ErrNotFound {
+		t.Errorf("expected error %v, found %v", mem.ErrNotFound, err)
+	}
+}
+
+func TestPersist(t *testing.T) {
+	s := mem.New()
+	defer s.Close()
+
+	if err := s.SetWithTimeout(context.Background(), "key", String("value"), time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Persist(context.Background(), "key"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok, _ := s.TTL(context.Background(), "key"); ok {
+		t.Error("expected no deadline after Persist")
+	}
+
+	if err := s.Persist(context.Background(), "unset key"); err != mem.ErrNotFound {
+		t.Errorf("expected error %v, found %v", mem.ErrNotFound, err)
+	}
+}
+
+func TestTouch(t *testing.T) {
+	s := mem.New()
+	defer s.Close()
+
+	if err := s.SetWithTimeout(context.Background(), "session", String("value"), time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Set(context.Background(), "persistent", String("value")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Touch(context.Background(), "session", time.Minute); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ttl, _, _ := s.TTL(context.Background(), "session"); ttl <= time.Second {
+		t.Errorf("expected the TTL to be extended, found %v", ttl)
+	}
+
+	if err := s.Touch(context.Background(), "session", time.Millisecond); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ttl, _, _ := s.TTL(context.Background(), "session"); ttl <= time.Second {
+		t.Errorf("expected the TTL not to be shortened, found %v", ttl)
+	}
+
+	if err := s.Touch(context.Background(), "persistent", time.Minute); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok, _ := s.TTL(context.Background(), "persistent"); ok {
+		t.Error("expected no deadline after Touch")
+	}
+
+	if err := s.Touch(context.Background(), "unset key", time.Minute); err != mem.ErrNotFound {
+		t.Errorf("expected error %v, found %v", mem.ErrNotFound, err)
+	}
+}
+
+func TestRetimeIsNotAWrite(t *testing.T) {
+	ctx := context.Background()
+
+	var evicted int
+	s := mem.New(mem.WithOnEvict(func(string, []byte, mem.EvictionReason) { evicted++ }))
+	defer s.Close()
+
+	s.SetWithTimeout(ctx, "k", String(`"v"`), time.Second)
+	before := s.Stats().Sets
+
+	s.Touch(ctx, "k", time.Minute)
+	s.Expire(ctx, "k", time.Hour)
+	s.Persist(ctx, "k")
+
+	if after := s.Stats().Sets; after != before {
+		t.Errorf("expected no set to be counted, found %d", after-before)
+	}
+	if evicted != 0 {
+		t.Errorf("expected no eviction callback, found %d", evicted)
+	}
+	if _, ok, _ := s.TTL(ctx, "k"); ok {
+		t.Error("expected no deadline after Persist")
+	}
+}
@@ -0,0 +1,114 @@
+package mem
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Tx is a transaction on a Store, as passed to the callback of Txn.
+// Writes are buffered and only applied to the Store when the callback
+// returns a nil error.
+//
+// A Tx is only valid for the duration of the callback and is not safe for
+// concurrent use.
+type Tx struct {
+	s      *Store
+	now    time.Time
+	writes batch
+}
+
+// batch holds buffered writes. A nil entry marks a deletion.
+type batch map[string]*entry
+
+// apply writes the batch to s. The caller must hold the write lock.
+func (b batch) apply(s *Store) {
+	for k, e := range b {
+		if e == nil {
+			s.remove(k, Deleted)
+			continue
+		}
+		s.put(k, *e)
+	}
+}
+
+// Txn runs fn in a transaction, holding the write lock of the Store for its
+// whole duration. If fn returns a nil error, the writes performed through
+// the Tx are applied atomically; otherwise they are discarded and the error
+// is returned.
+// fn must not call methods on the Store itself: it would deadlock.
+func (s *Store) Txn(ctx context.Context, fn func(tx *Tx) error) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	s.mu.Lock()
+	defer s.unlock()
+
+	tx := &Tx{
+		s:      s,
+		now:    s.clock.Now(),
+		writes: make(batch),
+	}
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+	if s.frozen && len(tx.writes) > 0 {
+		return ErrReadOnly
+	}
+	if err := s.admitAll(tx.writes); err != nil {
+		return err
+	}
+
+	tx.writes.apply(s)
+	return nil
+}
+
+// Get returns the value corresponding the key, as seen by the transaction.
+// If no match is found, returns (false, nil).
+func (tx *Tx) Get(k string, v json.Unmarshaler) (bool, error) {
+	e, ok := tx.writes[k]
+	if !ok {
+		stored, found := tx.s.m[k]
+		if !found || !stored.validAt(tx.now) {
+			return false, nil
+		}
+		e = &stored
+	}
+	if e == nil {
+		return false, nil
+	}
+	data, err := tx.s.value(*e)
+	if err != nil {
+		return true, err
+	}
+	return true, v.UnmarshalJSON(data)
+}
+
+// Set assigns the given value to the given key, possibly overwriting, when
+// the transaction is committed.
+// The returned error is not nil if marshaling fails.
+func (tx *Tx) Set(k string, v json.Marshaler) error {
+	b, err := tx.s.marshal(k, v)
+	if err != nil {
+		return err
+	}
+
+	e := tx.s.newEntry(k, b)
+	tx.writes[k] = &e
+	return nil
+}
+
+// Delete removes the corresponding entry when the transaction is committed.
+func (tx *Tx) Delete(k string) {
+	tx.writes[k] = nil
+}
@@ -0,0 +1,95 @@
+package mem_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gokv/mem"
+)
+
+func TestTxn(t *testing.T) {
+	newStore := func() *mem.Store {
+		s := mem.New()
+		if err := s.Set(context.Background(), "from", String("10")); err != nil {
+			panic(err)
+		}
+		return s
+	}
+
+	move := func(tx *mem.Tx) error {
+		var v String
+		ok, err := tx.Get("from", &v)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errors.New("source not found")
+		}
+		tx.Delete("from")
+		return tx.Set("to", v)
+	}
+
+	t.Run("commits on success", func(t *testing.T) {
+		s := newStore()
+		defer s.Close()
+
+		if err := s.Txn(context.Background(), move); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		var v String
+		if ok, _ := s.Get(context.Background(), "from", &v); ok {
+			t.Errorf("key %q unexpectedly found: %q", "from", v)
+		}
+		if ok, _ := s.Get(context.Background(), "to", &v); !ok || v != "10" {
+			t.Errorf("expected %q, found %q", "10", v)
+		}
+	})
+
+	t.Run("discards on error", func(t *testing.T) {
+		s := newStore()
+		defer s.Close()
+
+		failure := errors.New("failure")
+		err := s.Txn(context.Background(), func(tx *mem.Tx) error {
+			if err := move(tx); err != nil {
+				return err
+			}
+			return failure
+		})
+		if err != failure {
+			t.Errorf("expected error %v, found %v", failure, err)
+		}
+
+		var v String
+		if ok, _ := s.Get(context.Background(), "from", &v); !ok || v != "10" {
+			t.Errorf("expected %q, found %q", "10", v)
+		}
+		if ok, _ := s.Get(context.Background(), "to", &v); ok {
+			t.Errorf("key %q unexpectedly found: %q", "to", v)
+		}
+	})
+
+	t.Run("reads its own writes", func(t *testing.T) {
+		s := newStore()
+		defer s.Close()
+
+		err := s.Txn(context.Background(), func(tx *mem.Tx) error {
+			if err := tx.Set("from", String("20")); err != nil {
+				return err
+			}
+			var v String
+			if _, err := tx.Get("from", &v); err != nil {
+				return err
+			}
+			if v != "20" {
+				t.Errorf("expected %q, found %q", "20", v)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
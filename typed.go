@@ -0,0 +1,108 @@
+package mem
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Typed wraps a Store to read and write values of type V directly, encoding
+// them with the Codec of the Store, which defaults to JSON. V does not need
+// to implement json.Marshaler or json.Unmarshaler.
+//
+// Typed is safe for concurrent use.
+type Typed[V any] struct {
+	s *Store
+}
+
+// NewTyped returns a Typed view of s. Several views of different types can
+// share the same Store.
+func NewTyped[V any](s *Store) *Typed[V] {
+	return &Typed[V]{s: s}
+}
+
+// Store returns the underlying Store.
+func (t *Typed[V]) Store() *Store {
+	return t.s
+}
+
+// Get returns the value corresponding the key.
+// If no match is found, returns the zero value and false.
+func (t *Typed[V]) Get(ctx context.Context, k string) (V, bool, error) {
+	var v V
+	ok, err := t.s.Get(ctx, k, codecValue[V]{t.s.codec, &v})
+	return v, ok, err
+}
+
+// GetAll returns all values, in no particular order.
+// Err is non-nil if the context is Done or if decoding fails.
+func (t *Typed[V]) GetAll(ctx context.Context) ([]V, error) {
+	c := &codecCollection[V]{codec: t.s.codec}
+	if err := t.s.GetAll(ctx, c); err != nil {
+		return nil, err
+	}
+	return c.values, nil
+}
+
+// Add persists a new value and returns its unique key.
+// Err is non-nil in case of failure.
+func (t *Typed[V]) Add(ctx context.Context, v V) (string, error) {
+	b, err := t.s.codec.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return t.s.Add(ctx, raw(b))
+}
+
+// Set assigns the given value to the given key, possibly overwriting.
+// The returned error is not nil if the context is Done or if encoding fails.
+func (t *Typed[V]) Set(ctx context.Context, k string, v V) error {
+	b, err := t.s.codec.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return t.s.Set(ctx, k, raw(b))
+}
+
+// SetWithTimeout assigns the given value to the given key, possibly
+// overwriting.
+// The assigned key will clear after timeout.
+func (t *Typed[V]) SetWithTimeout(ctx context.Context, k string, v V, timeout time.Duration) error {
+	b, err := t.s.codec.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return t.s.SetWithTimeout(ctx, k, raw(b), timeout)
+}
+
+// Delete removes the corresponding entry if present.
+func (t *Typed[V]) Delete(ctx context.Context, k string) (bool, error) {
+	return t.s.Delete(ctx, k)
+}
+
+// raw is an already encoded value, stored as is.
+type raw []byte
+
+func (r raw) MarshalJSON() ([]byte, error) { return r, nil }
+
+// codecValue decodes into the value it points to with its Codec.
+type codecValue[V any] struct {
+	codec Codec
+	v     *V
+}
+
+func (c codecValue[V]) UnmarshalJSON(data []byte) error {
+	return c.codec.Unmarshal(data, c.v)
+}
+
+// codecCollection is a store.Collection of values decoded with its Codec.
+type codecCollection[V any] struct {
+	codec  Codec
+	values []V
+}
+
+func (c *codecCollection[V]) New() json.Unmarshaler {
+	var zero V
+	c.values = append(c.values, zero)
+	return codecValue[V]{c.codec, &c.values[len(c.values)-1]}
+}
@@ -0,0 +1,83 @@
+package mem_test
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/gokv/mem"
+)
+
+func TestTyped(t *testing.T) {
+	type user struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+
+	s := mem.New()
+	defer s.Close()
+
+	users := mem.NewTyped[user](s)
+	ctx := context.Background()
+
+	if err := users.Set(ctx, "ada", user{"Ada", 36}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	k, err := users.Add(ctx, user{"Alan", 41})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	u, ok, err := users.Get(ctx, "ada")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !ok || u != (user{"Ada", 36}) {
+		t.Errorf("expected %v, found %v", user{"Ada", 36}, u)
+	}
+
+	if _, ok, _ := users.Get(ctx, "unset key"); ok {
+		t.Error("expected a miss")
+	}
+
+	all, err := users.GetAll(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Name < all[j].Name })
+	if want := []user{{"Ada", 36}, {"Alan", 41}}; !reflect.DeepEqual(all, want) {
+		t.Errorf("expected %v, found %v", want, all)
+	}
+
+	if ok, _ := users.Delete(ctx, k); !ok {
+		t.Error("expected a deletion")
+	}
+}
+
+func TestTypedWithCodec(t *testing.T) {
+	type point struct{ X, Y int }
+
+	s := mem.New(mem.WithCodec(mem.Gob))
+	defer s.Close()
+
+	points := mem.NewTyped[point](s)
+	ctx := context.Background()
+
+	if err := points.Set(ctx, "origin", point{1, 2}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	p, ok, err := points.Get(ctx, "origin")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !ok || p != (point{1, 2}) {
+		t.Errorf("expected %v, found %v", point{1, 2}, p)
+	}
+
+	b, _, _ := s.GetBytes(ctx, "origin")
+	if b[0] == '{' {
+		t.Errorf("expected a gob encoding, found %q", b)
+	}
+}
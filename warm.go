@@ -0,0 +1,58 @@
+package mem
+
+import (
+	"context"
+
+	"github.com/gokv/store"
+)
+
+// WarmFrom pre-populates the Store with the values of keys in src, typically
+// a durable backend, so that the cache does not start cold. Keys missing from
+// src are skipped, and keys already in the Store are left untouched, as they
+// are more recent than src. The new entries are subject to the default TTL,
+// if set. Nothing is written unless all the keys are read successfully.
+// Err is non-nil if the context is Done, if reading from src fails, or if a
+// value is rejected by the validator.
+func (s *Store) WarmFrom(ctx context.Context, src store.Store, keys []string) error {
+	c := make(map[string]raw, len(keys))
+	for _, k := range keys {
+		var r raw
+		ok, err := src.Get(ctx, k, &r)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		if err := s.validate(k, r); err != nil {
+			return err
+		}
+		c[k] = r
+	}
+
+	s.mu.Lock()
+	defer s.unlock()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+	if s.frozen {
+		return ErrReadOnly
+	}
+
+	now := s.clock.Now()
+	writes := make(batch, len(c))
+	for k, r := range c {
+		if e, ok := s.m[k]; ok && e.validAt(now) {
+			continue
+		}
+		e := s.newEntry(k, r)
+		writes[k] = &e
+	}
+	if err := s.admitAll(writes); err != nil {
+		return err
+	}
+	writes.apply(s)
+	return nil
+}
@@ -0,0 +1,37 @@
+package mem_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gokv/mem"
+)
+
+func TestWarmFrom(t *testing.T) {
+	ctx := context.Background()
+
+	src := mem.New()
+	defer src.Close()
+
+	src.Set(ctx, "a", String(`"from source"`))
+	src.Set(ctx, "b", String(`"from source"`))
+
+	s := mem.New()
+	defer s.Close()
+
+	s.Set(ctx, "b", String(`"newer"`))
+
+	if err := s.WarmFrom(ctx, src, []string{"a", "b", "missing"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for k, want := range map[string]String{"a": `"from source"`, "b": `"newer"`} {
+		var v String
+		if ok, _ := s.Get(ctx, k, &v); !ok || v != want {
+			t.Errorf("expected %q, found %q", want, v)
+		}
+	}
+	if ok, _ := s.Exists(ctx, "missing"); ok {
+		t.Error("expected the keys missing from the source to be skipped")
+	}
+}
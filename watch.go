@@ -0,0 +1,131 @@
+package mem
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// ErrConflict is returned by Exec when a watched key was modified after
+// Watch. The transaction can be retried from a new call to Watch.
+var ErrConflict = errors.New("a watched key was modified")
+
+// WatchTx is an optimistic transaction on a Store.
+// Writes are buffered and applied by Exec, only if none of the watched keys
+// were modified in the meantime. The store lock is not held between calls.
+//
+// WatchTx is not safe for concurrent use.
+type WatchTx struct {
+	s       *Store
+	watched map[string]uint64
+	writes  batch
+}
+
+// Watch records the current version of the given keys and returns an
+// optimistic transaction guarded by them.
+// Err is non-nil if the context is Done.
+func (s *Store) Watch(ctx context.Context, keys ...string) (*WatchTx, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := s.clock.Now()
+
+	tx := &WatchTx{
+		s:       s,
+		watched: make(map[string]uint64, len(keys)),
+		writes:  make(batch),
+	}
+	for _, k := range keys {
+		tx.watched[k] = s.versionAt(k, now)
+	}
+	return tx, nil
+}
+
+// versionAt returns the version of the entry corresponding to the key, or
+// zero if it is missing or expired. The caller must hold the lock.
+func (s *Store) versionAt(k string, t time.Time) uint64 {
+	e, ok := s.m[k]
+	if !ok || !e.validAt(t) {
+		return 0
+	}
+	return e.version
+}
+
+// Get returns the value corresponding the key, including the writes buffered
+// in the transaction.
+// If no match is found, returns (false, nil).
+func (tx *WatchTx) Get(ctx context.Context, k string, v json.Unmarshaler) (bool, error) {
+	if e, ok := tx.writes[k]; ok {
+		if e == nil {
+			return false, nil
+		}
+		data, err := tx.s.value(*e)
+		if err != nil {
+			return true, err
+		}
+		return true, v.UnmarshalJSON(data)
+	}
+	return tx.s.Get(ctx, k, v)
+}
+
+// Set buffers the assignment of the given value to the given key.
+// The returned error is not nil if marshaling fails.
+func (tx *WatchTx) Set(k string, v json.Marshaler) error {
+	b, err := tx.s.marshal(k, v)
+	if err != nil {
+		return err
+	}
+
+	e := tx.s.newEntry(k, b)
+	tx.writes[k] = &e
+	return nil
+}
+
+// Delete buffers the removal of the corresponding entry.
+func (tx *WatchTx) Delete(k string) {
+	tx.writes[k] = nil
+}
+
+// Exec atomically applies the buffered writes.
+// Returns ErrConflict, and applies nothing, if any of the watched keys were
+// modified, deleted or expired since Watch.
+func (tx *WatchTx) Exec(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	s := tx.s
+
+	s.mu.Lock()
+	defer s.unlock()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+	if s.frozen {
+		return ErrReadOnly
+	}
+
+	now := tx.s.clock.Now()
+	for k, version := range tx.watched {
+		if s.versionAt(k, now) != version {
+			return ErrConflict
+		}
+	}
+	if err := s.admitAll(tx.writes); err != nil {
+		return err
+	}
+
+	tx.writes.apply(s)
+	return nil
+}
@@ -0,0 +1,89 @@
+package mem_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gokv/mem"
+)
+
+func TestWatch(t *testing.T) {
+	incr := func(s *mem.Store, interfere func()) error {
+		tx, err := s.Watch(context.Background(), "counter")
+		if err != nil {
+			return err
+		}
+
+		var v String
+		if _, err := tx.Get(context.Background(), "counter", &v); err != nil {
+			return err
+		}
+		if err := tx.Set("counter", v+"+1"); err != nil {
+			return err
+		}
+
+		interfere()
+		return tx.Exec(context.Background())
+	}
+
+	t.Run("applies when unchanged", func(t *testing.T) {
+		s := mem.New()
+		defer s.Close()
+
+		if err := s.Set(context.Background(), "counter", String("0")); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := incr(s, func() {}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		var v String
+		if _, err := s.Get(context.Background(), "counter", &v); err != nil {
+			t.Fatal(err)
+		}
+		if v != "0+1" {
+			t.Errorf("expected %q, found %q", "0+1", v)
+		}
+	})
+
+	t.Run("aborts on conflict", func(t *testing.T) {
+		s := mem.New()
+		defer s.Close()
+
+		if err := s.Set(context.Background(), "counter", String("0")); err != nil {
+			t.Fatal(err)
+		}
+
+		err := incr(s, func() {
+			if err := s.Set(context.Background(), "counter", String("5")); err != nil {
+				t.Fatal(err)
+			}
+		})
+		if err != mem.ErrConflict {
+			t.Errorf("expected error %v, found %v", mem.ErrConflict, err)
+		}
+
+		var v String
+		if _, err := s.Get(context.Background(), "counter", &v); err != nil {
+			t.Fatal(err)
+		}
+		if v != "5" {
+			t.Errorf("expected %q, found %q", "5", v)
+		}
+	})
+
+	t.Run("aborts when a watched key is created", func(t *testing.T) {
+		s := mem.New()
+		defer s.Close()
+
+		err := incr(s, func() {
+			if err := s.Set(context.Background(), "counter", String("5")); err != nil {
+				t.Fatal(err)
+			}
+		})
+		if err != mem.ErrConflict {
+			t.Errorf("expected error %v, found %v", mem.ErrConflict, err)
+		}
+	})
+}
@@ -0,0 +1,117 @@
+package mem
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gokv/store"
+)
+
+// pendingWrite is a mutation waiting to be flushed to the backing store.
+type pendingWrite struct {
+	data     []byte
+	deadline time.Time // zero means no deadline
+	del      bool
+}
+
+// apply writes the mutation of k to backing.
+func (w pendingWrite) apply(ctx context.Context, backing store.Store, k string) error {
+	switch {
+	case w.del:
+		_, err := backing.Delete(ctx, k)
+		return err
+	case w.deadline.IsZero():
+		return backing.Set(ctx, k, raw(w.data))
+	default:
+		return backing.SetWithDeadline(ctx, k, raw(w.data), w.deadline)
+	}
+}
+
+// writeBehind queues the mutations of a Store and flushes them to a backing
+// store asynchronously. Successive mutations of a key are coalesced, so that
+// only the last one is flushed.
+type writeBehind struct {
+	backing   store.Store
+	queueSize int
+	onError   func(k string, err error)
+
+	mu      sync.Mutex
+	pending map[string]pendingWrite
+	full    chan struct{} // signals that the queue reached queueSize
+
+	flushMu sync.Mutex // serializes the flushes
+}
+
+func newWriteBehind(backing store.Store, queueSize int) *writeBehind {
+	return &writeBehind{
+		backing:   backing,
+		queueSize: queueSize,
+		pending:   make(map[string]pendingWrite),
+		full:      make(chan struct{}, 1),
+	}
+}
+
+// enqueue queues the mutation of k, replacing any pending one.
+func (wb *writeBehind) enqueue(k string, w pendingWrite) {
+	wb.mu.Lock()
+	wb.pending[k] = w
+	n := len(wb.pending)
+	wb.mu.Unlock()
+
+	if wb.queueSize > 0 && n >= wb.queueSize {
+		select {
+		case wb.full <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// flush writes the pending mutations to the backing store. Failed mutations
+// are reported to onError, and dropped.
+func (wb *writeBehind) flush(ctx context.Context) {
+	wb.flushMu.Lock()
+	defer wb.flushMu.Unlock()
+
+	wb.mu.Lock()
+	pending := wb.pending
+	wb.pending = make(map[string]pendingWrite)
+	wb.mu.Unlock()
+
+	for k, w := range pending {
+		if err := w.apply(ctx, wb.backing, k); err != nil && wb.onError != nil {
+			wb.onError(k, err)
+		}
+	}
+}
+
+// start flushes every positive interval, or as soon as the queue is full, until stop
+// is called. Stop flushes the remaining mutations, and returns when done.
+func (wb *writeBehind) start(interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+
+		var tick <-chan time.Time // nil, never ready, without an interval
+		if interval > 0 {
+			t := time.NewTicker(interval)
+			defer t.Stop()
+			tick = t.C
+		}
+		for {
+			select {
+			case <-done:
+				return
+			case <-tick:
+			case <-wb.full:
+			}
+			wb.flush(context.Background())
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+		wb.flush(context.Background())
+	}
+}
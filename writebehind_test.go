@@ -0,0 +1,103 @@
+package mem_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gokv/mem"
+)
+
+func TestWithWriteBehind(t *testing.T) {
+	ctx := context.Background()
+
+	backing := mem.New()
+	defer backing.Close()
+
+	s := mem.New(mem.WithWriteBehind(backing, 10*time.Millisecond, 0))
+
+	s.Set(ctx, "key", String(`"first"`))
+	s.Set(ctx, "key", String(`"last"`))
+	s.Set(ctx, "deleted", String(`"v"`))
+	s.Delete(ctx, "deleted")
+
+	if ok, _ := backing.Exists(ctx, "key"); ok {
+		t.Error("expected the write to be deferred")
+	}
+
+	time.Sleep(50 * time.Millisecond)
+
+	var v String
+	if ok, _ := backing.Get(ctx, "key", &v); !ok || v != `"last"` {
+		t.Errorf("expected %q, found %q", `"last"`, v)
+	}
+	if ok, _ := backing.Exists(ctx, "deleted"); ok {
+		t.Error("expected the deletion to be flushed")
+	}
+
+	s.Set(ctx, "on close", String(`"v"`))
+	s.Close()
+	if ok, _ := backing.Exists(ctx, "on close"); !ok {
+		t.Error("expected Close to flush")
+	}
+
+	t.Run("flushes when the queue is full", func(t *testing.T) {
+		s := mem.New(mem.WithWriteBehind(backing, 0, 2))
+		defer s.Close()
+
+		s.Set(ctx, "a", String(`"v"`))
+		s.Set(ctx, "b", String(`"v"`))
+
+		time.Sleep(20 * time.Millisecond)
+		if ok, _ := backing.Exists(ctx, "b"); !ok {
+			t.Error("expected the full queue to be flushed")
+		}
+	})
+
+	t.Run("reports flush errors", func(t *testing.T) {
+		var mu sync.Mutex
+		var failed []string
+		s := mem.New(
+			mem.WithWriteBehind(failingStore{backing}, time.Hour, 0),
+			mem.WithOnFlushError(func(k string, err error) {
+				mu.Lock()
+				defer mu.Unlock()
+				if err == errBacking {
+					failed = append(failed, k)
+				}
+			}),
+		)
+
+		if err := s.Set(ctx, "key", String(`"v"`)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		s.Close()
+
+		mu.Lock()
+		defer mu.Unlock()
+		if len(failed) != 1 || failed[0] != "key" {
+			t.Errorf("expected the failure of %q to be reported, found %v", "key", failed)
+		}
+	})
+}
+
+func TestWriteBehindMirrorsEveryWrite(t *testing.T) {
+	ctx := context.Background()
+
+	backing := mem.New()
+	defer backing.Close()
+
+	s := mem.New(mem.WithWriteBehind(backing, 0, 0))
+	for _, m := range mutators {
+		if m.name == "Clear" {
+			// keep entries to compare
+			break
+		}
+		if err := m.fn(ctx, s); err != nil {
+			t.Fatalf("%s: unexpected error: %v", m.name, err)
+		}
+	}
+	s.Close()
+	sameEntries(t, s, backing)
+}
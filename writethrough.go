@@ -0,0 +1,74 @@
+package mem
+
+import (
+	"context"
+	"time"
+)
+
+// mirrorSet writes the plaintext value b to the backing store, if set, or
+// queues it for write-behind. A zero deadline means no deadline.
+// The caller must hold the write lock, so that the writes reach the backing
+// store in the order they are applied in memory, and must have checked that
+// the Store is not frozen and that the write is within quota.
+func (s *Store) mirrorSet(ctx context.Context, k string, b []byte, deadline time.Time) error {
+	if s.behind != nil {
+		s.behind.enqueue(k, pendingWrite{data: b, deadline: deadline})
+		return nil
+	}
+	if s.backing == nil {
+		return nil
+	}
+	return pendingWrite{data: b, deadline: deadline}.apply(ctx, s.backing, k)
+}
+
+// mirrorDelete deletes k from the backing store, if set, or queues the
+// deletion for write-behind. The caller must hold the write lock, and must
+// have checked that the Store is not frozen.
+func (s *Store) mirrorDelete(ctx context.Context, k string) (bool, error) {
+	if s.behind != nil {
+		s.behind.enqueue(k, pendingWrite{del: true})
+		return false, nil
+	}
+	if s.backing == nil {
+		return false, nil
+	}
+	return s.backing.Delete(ctx, k)
+}
+
+// mirror mirrors the write of e to k, or the deletion of k if e is nil,
+// after it was applied in memory: to the backing store, if set, or to the
+// write-behind queue. Failures are reported to the function set
+// WithOnFlushError. The caller must hold the write lock.
+func (s *Store) mirror(k string, e *entry) {
+	if s.backing == nil && s.behind == nil {
+		return
+	}
+
+	w := pendingWrite{del: e == nil}
+	if e != nil {
+		data, err := s.value(*e)
+		if err != nil {
+			s.mirrorFailed(k, err)
+			return
+		}
+		w.data = data
+		if e.validTo != 0 {
+			w.deadline = time.Unix(0, e.validTo)
+		}
+	}
+
+	if s.behind != nil {
+		s.behind.enqueue(k, w)
+		return
+	}
+	if err := w.apply(context.Background(), s.backing, k); err != nil {
+		s.mirrorFailed(k, err)
+	}
+}
+
+// mirrorFailed reports the failure to mirror k.
+func (s *Store) mirrorFailed(k string, err error) {
+	if s.onFlushError != nil {
+		s.onFlushError(k, err)
+	}
+}
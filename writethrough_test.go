@@ -0,0 +1,296 @@
+package mem_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gokv/mem"
+)
+
+var errBacking = errors.New("backing store failure")
+
+// failingStore is a store whose writes fail.
+type failingStore struct {
+	*mem.Store
+}
+
+func (failingStore) Set(context.Context, string, json.Marshaler) error { return errBacking }
+
+func (failingStore) SetWithDeadline(context.Context, string, json.Marshaler, time.Time) error {
+	return errBacking
+}
+
+func (failingStore) Delete(context.Context, string) (bool, error) { return false, errBacking }
+
+func TestWithWriteThrough(t *testing.T) {
+	ctx := context.Background()
+
+	backing := mem.New()
+	defer backing.Close()
+
+	s := mem.New(mem.WithWriteThrough(backing))
+	defer s.Close()
+
+	s.Set(ctx, "set", String(`"a"`))
+	s.SetWithTimeout(ctx, "volatile", String(`"b"`), time.Minute)
+	k, _ := s.Add(ctx, String(`"c"`))
+
+	for _, k := range []string{"set", "volatile", k} {
+		if ok, _ := backing.Exists(ctx, k); !ok {
+			t.Errorf("expected %q to be mirrored", k)
+		}
+	}
+	if _, ok, _ := backing.TTL(ctx, "volatile"); !ok {
+		t.Error("expected the deadline to be mirrored")
+	}
+
+	backing.Set(ctx, "only in backing", String(`"d"`))
+	if ok, _ := s.Delete(ctx, "only in backing"); !ok {
+		t.Error("expected the deletion to be reported")
+	}
+	if ok, _ := backing.Exists(ctx, "only in backing"); ok {
+		t.Error("expected the deletion to be mirrored")
+	}
+
+	t.Run("Bucket and Sharded", func(t *testing.T) {
+		k, err := s.Bucket("b").Add(ctx, String(`"e"`))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if ok, _ := backing.Exists(ctx, "b\x00"+k); !ok {
+			t.Errorf("expected %q to be mirrored", k)
+		}
+
+		sharded := mem.NewSharded(2, mem.WithWriteThrough(backing))
+		defer sharded.Close()
+		k, err = sharded.Add(ctx, String(`"f"`))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if ok, _ := backing.Exists(ctx, k); !ok {
+			t.Errorf("expected %q to be mirrored", k)
+		}
+	})
+
+	t.Run("concurrent writes", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				s.Set(ctx, "contended", String(strconv.Itoa(i)))
+			}(i)
+		}
+		wg.Wait()
+
+		var inMemory, inBacking String
+		s.Get(ctx, "contended", &inMemory)
+		backing.Get(ctx, "contended", &inBacking)
+		if inMemory != inBacking {
+			t.Errorf("expected the same last write, found %s in memory and %s in backing", inMemory, inBacking)
+		}
+	})
+
+	t.Run("backing failure", func(t *testing.T) {
+		s := mem.New(mem.WithWriteThrough(failingStore{backing}))
+		defer s.Close()
+
+		if err := s.Set(ctx, "key", String(`"v"`)); err != errBacking {
+			t.Errorf("expected error %v, found %v", errBacking, err)
+		}
+		if _, err := s.Add(ctx, String(`"v"`)); err != errBacking {
+			t.Errorf("expected error %v, found %v", errBacking, err)
+		}
+		if n, _ := s.Len(ctx); n != 0 {
+			t.Errorf("expected the Store to be unchanged, found %d entries", n)
+		}
+	})
+}
+
+// mutator is a write of a Store, to be mirrored to its backing store.
+type mutator struct {
+	name string
+	fn   func(ctx context.Context, s *mem.Store) error
+}
+
+// mutators covers the ways to write to a Store.
+var mutators = []mutator{
+	{"Set", func(ctx context.Context, s *mem.Store) error {
+		return s.Set(ctx, "a", String(`"a"`))
+	}},
+	{"SetNX", func(ctx context.Context, s *mem.Store) error {
+		_, err := s.SetNX(ctx, "nx", String(`"nx"`))
+		return err
+	}},
+	{"CompareAndSwap", func(ctx context.Context, s *mem.Store) error {
+		_, err := s.CompareAndSwap(ctx, "a", String(`"a"`), String(`"swapped"`))
+		return err
+	}},
+	{"GetSet", func(ctx context.Context, s *mem.Store) error {
+		_, err := s.GetSet(ctx, "a", String(`"getset"`), new(String))
+		return err
+	}},
+	{"GetOrSet", func(ctx context.Context, s *mem.Store) error {
+		_, err := s.GetOrSet(ctx, "getorset", String(`"g"`), new(String))
+		return err
+	}},
+	{"Incr", func(ctx context.Context, s *mem.Store) error {
+		_, err := s.Incr(ctx, "counter", 2)
+		return err
+	}},
+	{"Decr", func(ctx context.Context, s *mem.Store) error {
+		_, err := s.Decr(ctx, "counter", 1)
+		return err
+	}},
+	{"Append", func(ctx context.Context, s *mem.Store) error {
+		_, err := s.Append(ctx, "counter", []byte("0"))
+		return err
+	}},
+	{"CompareAndDelete", func(ctx context.Context, s *mem.Store) error {
+		_, err := s.CompareAndDelete(ctx, "getorset", String(`"g"`))
+		return err
+	}},
+	{"GetAndDelete", func(ctx context.Context, s *mem.Store) error {
+		_, err := s.GetAndDelete(ctx, "nx", new(String))
+		return err
+	}},
+	{"SetMulti", func(ctx context.Context, s *mem.Store) error {
+		return s.SetMulti(ctx, map[string]json.Marshaler{
+			"m1": String(`1`), "m2": String(`2`), "m3": String(`3`), "p/1": String(`4`), "p/2": String(`5`),
+		})
+	}},
+	{"DeleteMulti", func(ctx context.Context, s *mem.Store) error {
+		_, err := s.DeleteMulti(ctx, "m1")
+		return err
+	}},
+	{"DeleteByPrefix", func(ctx context.Context, s *mem.Store) error {
+		_, err := s.DeleteByPrefix(ctx, "p/")
+		return err
+	}},
+	{"DeleteWhere", func(ctx context.Context, s *mem.Store) error {
+		_, err := s.DeleteWhere(ctx, func(k string, _ []byte) bool { return k == "m2" })
+		return err
+	}},
+	{"SetTagged and DeleteByTag", func(ctx context.Context, s *mem.Store) error {
+		s.SetTagged(ctx, "t1", String(`"t"`), "tag")
+		s.SetTagged(ctx, "t2", String(`"t"`), "tag")
+		_, err := s.DeleteByTag(ctx, "tag")
+		return err
+	}},
+	{"Txn", func(ctx context.Context, s *mem.Store) error {
+		return s.Txn(ctx, func(tx *mem.Tx) error {
+			tx.Delete("m3")
+			return tx.Set("txn", String(`"txn"`))
+		})
+	}},
+	{"WatchTx", func(ctx context.Context, s *mem.Store) error {
+		tx, err := s.Watch(ctx, "txn")
+		if err != nil {
+			return err
+		}
+		tx.Set("watched", String(`"w"`))
+		return tx.Exec(ctx)
+	}},
+	{"SetWithIdleTimeout", func(ctx context.Context, s *mem.Store) error {
+		return s.SetWithIdleTimeout(ctx, "idle", String(`"i"`), time.Hour)
+	}},
+	{"Expire", func(ctx context.Context, s *mem.Store) error {
+		return s.Expire(ctx, "a", time.Hour)
+	}},
+	{"Persist", func(ctx context.Context, s *mem.Store) error {
+		return s.Persist(ctx, "idle")
+	}},
+	{"Lock and Unlock", func(ctx context.Context, s *mem.Store) error {
+		s.Lock(ctx, "held", time.Hour)
+		token, err := s.Lock(ctx, "released", time.Hour)
+		if err != nil {
+			return err
+		}
+		return s.Unlock(ctx, "released", token)
+	}},
+	{"Allow", func(ctx context.Context, s *mem.Store) error {
+		_, err := s.Allow(ctx, "rate", 10, time.Hour)
+		return err
+	}},
+	{"ImportJSON", func(ctx context.Context, s *mem.Store) error {
+		return s.ImportJSON(ctx, strings.NewReader(`{"imported": {"value": "i"}}`))
+	}},
+	{"Merge", func(ctx context.Context, s *mem.Store) error {
+		other := mem.New()
+		defer other.Close()
+		other.Set(ctx, "merged", String(`"m"`))
+		return s.Merge(ctx, other, mem.Overwrite)
+	}},
+	{"LoadFrom", func(ctx context.Context, s *mem.Store) error {
+		other := mem.New()
+		defer other.Close()
+		other.Set(ctx, "loaded", String(`"l"`))
+		var buf bytes.Buffer
+		if err := other.SaveTo(ctx, &buf); err != nil {
+			return err
+		}
+		return s.LoadFrom(ctx, &buf)
+	}},
+	{"WarmFrom", func(ctx context.Context, s *mem.Store) error {
+		other := mem.New()
+		defer other.Close()
+		other.Set(ctx, "warmed", String(`"w"`))
+		return s.WarmFrom(ctx, other, []string{"warmed"})
+	}},
+	{"Clear", func(ctx context.Context, s *mem.Store) error {
+		return s.Clear(ctx)
+	}},
+}
+
+// sameEntries fails t unless s and backing hold the same values, with
+// deadlines for the same keys.
+func sameEntries(t *testing.T, s, backing *mem.Store) {
+	t.Helper()
+	ctx := context.Background()
+
+	have, want := make(keyedCollection), make(keyedCollection)
+	if err := backing.GetAllKeyed(ctx, have); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.GetAllKeyed(ctx, want); err != nil {
+		t.Fatal(err)
+	}
+	if len(have) != len(want) {
+		t.Errorf("expected %d entries in the backing store, found %d", len(want), len(have))
+	}
+	for k, v := range want {
+		if b, ok := have[k]; !ok || *b != *v {
+			t.Errorf("expected %q for key %q in the backing store, found %v", *v, k, b)
+		}
+		_, expires, _ := s.TTL(ctx, k)
+		if _, ok, _ := backing.TTL(ctx, k); ok != expires {
+			t.Errorf("expected the deadline of %q to be mirrored", k)
+		}
+	}
+}
+
+func TestWriteThroughMirrorsEveryWrite(t *testing.T) {
+	ctx := context.Background()
+
+	backing := mem.New()
+	defer backing.Close()
+
+	s := mem.New(mem.WithWriteThrough(backing))
+	defer s.Close()
+
+	for _, m := range mutators {
+		if err := m.fn(ctx, s); err != nil {
+			t.Fatalf("%s: unexpected error: %v", m.name, err)
+		}
+		t.Run(m.name, func(t *testing.T) {
+			sameEntries(t, s, backing)
+		})
+	}
+}